@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultAdaptiveInterval = time.Second // How often an adaptive queue adjusts its active workers if AdaptiveConcurrency.Interval is not set
+	DefaultAdaptiveDecrease = 0.5         // Factor by which an adaptive queue shrinks its active workers if AdaptiveConcurrency.Decrease is not set
+)
+
+// AdaptiveConcurrency configures an AIMD controller that adjusts the number of
+// active workers of a queue: every interval, the active workers shrink by a
+// factor if the tasks that finished during the interval were too slow or
+// panicked too often, and otherwise grow by one while tasks are queued.
+type AdaptiveConcurrency struct {
+	MinWorkers    int           // Minimum number of active workers; values below 1 are treated as 1
+	MaxWorkers    int           // Maximum number of active workers, which is the number of workers that are started; defaults to the workers passed to NewQueue
+	TargetLatency time.Duration // Maximum average task latency before the active workers shrink; zero means latency is ignored
+	MaxErrorRate  float64       // Maximum fraction of panicking tasks before the active workers shrink
+	Interval      time.Duration // How often the active workers are adjusted
+	Decrease      float64       // Factor by which the active workers shrink, between 0 and 1
+}
+
+// WithAdaptiveConcurrency makes the queue adjust the number of workers that
+// run tasks at once between the bounds of config, so that it tunes itself
+// instead of requiring a hard-coded size. The workers passed to NewQueue are
+// the initial number of active workers.
+func WithAdaptiveConcurrency(config AdaptiveConcurrency) QueueOption {
+	return func(o *queueOptions) {
+		o.adaptive = &config
+	}
+}
+
+// waitActive waits until the worker with index i may run tasks. It returns
+// false if ctx is done first.
+func (q *Queue) waitActive(ctx context.Context, i int) bool {
+	for {
+		q.lock.Lock()
+		active, changed := q.active, q.activeChanged
+		q.lock.Unlock()
+
+		if i < active {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+
+		case <-changed:
+		}
+	}
+}
+
+// observe records the latency of a finished task and whether it panicked
+func (q *Queue) observe(latency time.Duration, panicked bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.window.tasks++
+	q.window.latency += latency
+	if panicked {
+		q.window.panics++
+	}
+}
+
+// adapt adjusts the number of active workers based on the tasks that finished
+// since the last adjustment
+func (q *Queue) adapt(_ context.Context) {
+	config := q.options.adaptive
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	window := q.window
+	q.window = queueWindow{}
+
+	active := q.active
+	if window.tasks > 0 &&
+		((config.TargetLatency > 0 && window.latency/time.Duration(window.tasks) > config.TargetLatency) ||
+			float64(window.panics)/float64(window.tasks) > config.MaxErrorRate) {
+		active = max(int(float64(active)*config.Decrease), config.MinWorkers)
+	} else if q.depth > 0 {
+		active = min(active+1, config.MaxWorkers)
+	}
+
+	if active == q.active {
+		return
+	}
+
+	q.m.options.logger.Debug("adjusted active queue workers", "queue", q.name, "from", q.active, "to", active)
+
+	q.active = active
+
+	close(q.activeChanged)
+	q.activeChanged = make(chan struct{})
+}
+
+// normalize fills in the defaults of the configuration and clamps the initial
+// number of active workers to its bounds
+func (c *AdaptiveConcurrency) normalize(workers int) int {
+	if c.MaxWorkers <= 0 {
+		c.MaxWorkers = workers
+	}
+
+	c.MinWorkers = max(c.MinWorkers, 1)
+	c.MaxWorkers = max(c.MaxWorkers, c.MinWorkers)
+
+	if c.Interval <= 0 {
+		c.Interval = DefaultAdaptiveInterval
+	}
+
+	if c.Decrease <= 0 || c.Decrease >= 1 {
+		c.Decrease = DefaultAdaptiveDecrease
+	}
+
+	return min(max(workers, c.MinWorkers), c.MaxWorkers)
+}
@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveConcurrencyGrow(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 1, 10, WithAdaptiveConcurrency(AdaptiveConcurrency{
+		MaxWorkers: 3,
+		Interval:   5 * time.Millisecond,
+	}))
+
+	require.Equal(t, 3, q.Stats().Workers)
+	require.Equal(t, 1, q.Stats().Active)
+
+	var running atomic.Int64
+	release := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+			running.Add(1)
+			defer running.Add(-1)
+
+			<-release
+		}))
+	}
+
+	// Verify the active workers grow while tasks are queued, up to the maximum.
+	require.Eventually(t, func() bool {
+		return running.Load() == 3
+	}, time.Second, time.Millisecond)
+	require.Equal(t, 3, q.Stats().Active)
+
+	close(release)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestAdaptiveConcurrencyDefaults(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 4, 10, WithAdaptiveConcurrency(AdaptiveConcurrency{}))
+
+	// Verify the workers passed to NewQueue are the default maximum.
+	require.Equal(t, 4, q.Stats().Workers)
+	require.Equal(t, 4, q.Stats().Active)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestAdaptiveConcurrencyShrink(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 4, 10, WithWorkerStartOptions(WithPanicPolicy(PanicPolicyRecord)), WithAdaptiveConcurrency(AdaptiveConcurrency{
+		MinWorkers: 1,
+		MaxWorkers: 4,
+		Interval:   5 * time.Millisecond,
+	}))
+
+	require.Equal(t, 4, q.Stats().Active)
+
+	// Verify the active workers shrink to the minimum while tasks panic.
+	require.Eventually(t, func() bool {
+		_ = q.TryEnqueue(func(_ context.Context) {
+			panic(testErr)
+		})
+
+		return q.Stats().Active == 1
+	}, time.Second, time.Millisecond)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.ErrorIs(t, errs, testErr)
+}
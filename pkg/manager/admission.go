@@ -0,0 +1,138 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// DefaultAdmissionCheckInterval is how often paused goroutines check whether
+// they can be admitted if AdmissionPolicy.CheckInterval is not set
+const DefaultAdmissionCheckInterval = 100 * time.Millisecond
+
+var (
+	ErrGoroutineRejected = errors.New("goroutine rejected by admission control") // Collected if a goroutine is rejected by an admission policy with AdmissionModeReject
+)
+
+// AdmissionMode configures what happens to goroutines that are started while
+// the thresholds of an admission policy are exceeded
+type AdmissionMode int
+
+const (
+	AdmissionModePause  AdmissionMode = iota // Goroutines wait until the usage is below the thresholds or the goroutine context is cancelled (default)
+	AdmissionModeReject                      // Goroutines exit without running and an error wrapping ErrGoroutineRejected is collected
+)
+
+// AdmissionPolicy configures the thresholds of admission control
+type AdmissionPolicy struct {
+	MaxHeapBytes  uint64        // Maximum heap usage at which goroutines are admitted; zero means no limit
+	MaxGoroutines int           // Maximum number of admitted goroutines running at once; zero means no limit
+	Mode          AdmissionMode // What happens to goroutines that can't be admitted
+	CheckInterval time.Duration // How often paused goroutines check whether they can be admitted
+}
+
+// AdmissionUsage contains the usage that admission control compares against
+// the thresholds of its policy
+type AdmissionUsage struct {
+	HeapBytes  uint64 // Memory occupied by live and not yet swept heap objects, if MaxHeapBytes is set
+	Goroutines int    // Number of admitted goroutines that are running
+}
+
+// admission implements admission control
+type admission struct {
+	policy AdmissionPolicy
+
+	lock     sync.Mutex
+	admitted int
+	exceeded bool
+}
+
+// tryAdmit admits a goroutine if the usage is below the thresholds. It returns
+// the usage and whether the thresholds have just become exceeded.
+func (a *admission) tryAdmit() (bool, AdmissionUsage, bool) {
+	var usage AdmissionUsage
+	if a.policy.MaxHeapBytes > 0 {
+		sample := []metrics.Sample{{Name: runtimeMetricHeapBytes}}
+		metrics.Read(sample)
+
+		if sample[0].Value.Kind() == metrics.KindUint64 {
+			usage.HeapBytes = sample[0].Value.Uint64()
+		}
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	usage.Goroutines = a.admitted
+
+	exceeded := (a.policy.MaxGoroutines > 0 && a.admitted >= a.policy.MaxGoroutines) ||
+		(a.policy.MaxHeapBytes > 0 && usage.HeapBytes > a.policy.MaxHeapBytes)
+
+	alert := exceeded && !a.exceeded
+	a.exceeded = exceeded
+
+	if !exceeded {
+		a.admitted++
+	}
+
+	return !exceeded, usage, alert
+}
+
+func (a *admission) release() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.admitted--
+}
+
+// admit waits until a goroutine is admitted by the admission policy. It
+// returns a function that releases the admission, or false if the goroutine
+// was rejected or ctx is done first.
+func (m *GoroutineManager) admit(ctx context.Context, g *goroutine) (func(), bool) {
+	a := m.admission
+	if a == nil {
+		return func() {}, true
+	}
+
+	interval := a.policy.CheckInterval
+	if interval <= 0 {
+		interval = DefaultAdmissionCheckInterval
+	}
+
+	for {
+		admitted, usage, alert := a.tryAdmit()
+		if alert {
+			m.options.logger.Warn("admission thresholds exceeded", "heapBytes", usage.HeapBytes, "goroutines", usage.Goroutines)
+
+			if hook := m.options.onAdmissionExceeded; hook != nil {
+				m.callHook("OnAdmissionExceeded", func() {
+					hook(usage)
+				})
+			}
+		}
+
+		if admitted {
+			return a.release, true
+		}
+
+		if a.policy.Mode == AdmissionModeReject {
+			m.collectError(fmt.Errorf("could not start goroutine %q: %w", g.options.name, ErrGoroutineRejected))
+
+			return nil, false
+		}
+
+		timer := m.options.clock.NewTimer(interval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, false
+
+		case <-timer.C():
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionControlPause(t *testing.T) {
+	t.Parallel()
+
+	var alerts atomic.Int64
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithAdmissionControl(AdmissionPolicy{
+		MaxGoroutines: 1,
+		CheckInterval: time.Millisecond,
+	}, func(usage AdmissionUsage) {
+		if usage.Goroutines == 1 {
+			alerts.Add(1)
+		}
+	}))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		close(started)
+
+		<-release
+	})
+	<-started
+
+	var ran atomic.Bool
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		ran.Store(true)
+	})
+
+	// Verify the second goroutine is paused until the first one finishes.
+	require.Eventually(t, func() bool {
+		return alerts.Load() == 1
+	}, time.Second, time.Millisecond)
+	require.False(t, ran.Load())
+
+	close(release)
+	m.Wait()
+
+	require.True(t, ran.Load())
+	require.NoError(t, errs)
+}
+
+func TestAdmissionControlReject(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithAdmissionControl(AdmissionPolicy{
+		MaxHeapBytes: 1,
+		Mode:         AdmissionModeReject,
+	}, nil))
+
+	var ran atomic.Bool
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		ran.Store(true)
+	}, WithGoroutineName("worker"))
+	m.Wait()
+
+	// Verify the goroutine doesn't run since the heap is always above one byte.
+	require.False(t, ran.Load())
+	require.ErrorIs(t, errs, ErrGoroutineRejected)
+	require.ErrorContains(t, errs, `"worker"`)
+}
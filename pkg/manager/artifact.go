@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// panicArtifactTimeFormat is the format of the time of the panic in the names
+// of panic artifacts
+const panicArtifactTimeFormat = "20060102T150405.000000000Z"
+
+// panicArtifact is the content of a file written with WithPanicArtifactDir
+type panicArtifact struct {
+	Panic   jsonError                 `json:"panic"`
+	Tags    []string                  `json:"tags,omitempty"`
+	Manager panicArtifactManagerState `json:"manager"`
+}
+
+// panicArtifactManagerState is a snapshot of the state of the goroutine
+// manager at the time of a panic
+type panicArtifactManagerState struct {
+	Name       string          `json:"name,omitempty"`
+	State      string          `json:"state"`
+	Foreground GoroutineCounts `json:"foreground"`
+	Background GoroutineCounts `json:"background"`
+	Running    []jsonGoroutine `json:"running"` // Foreground goroutines that haven't finished yet
+	Errors     int             `json:"errors"`  // Number of errors collected so far
+}
+
+// writePanicArtifact writes a file with the panic e recovered from goroutine g
+// and a snapshot of the manager's state to the panic artifact directory
+func (m *GoroutineManager) writePanicArtifact(g *goroutine, e *PanicError) {
+	dir := m.options.panicArtifactDir
+
+	stats := m.Stats()
+	artifact := panicArtifact{
+		Panic: newJSONError(e),
+		Tags:  g.options.tags,
+		Manager: panicArtifactManagerState{
+			Name:       m.name,
+			State:      m.State().String(),
+			Foreground: stats.Foreground,
+			Background: stats.Background,
+			Running:    []jsonGoroutine{},
+			Errors:     len(m.AllErrors()),
+		},
+	}
+
+	for _, r := range m.tracker.remaining() {
+		artifact.Manager.Running = append(artifact.Manager.Running, jsonGoroutine{
+			ID:      r.managedID,
+			Name:    r.options.name,
+			Started: r.started,
+			Runtime: e.Time.Sub(r.started).String(),
+		})
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		m.options.logger.Error("could not create panic artifact directory", "dir", dir, "error", err)
+
+		return
+	}
+
+	// The random suffix keeps simultaneous panics from overwriting each other
+	f, err := os.CreateTemp(dir, "panic-"+e.Time.UTC().Format(panicArtifactTimeFormat)+"-*.json")
+	if err != nil {
+		m.options.logger.Error("could not create panic artifact", "dir", dir, "error", err)
+
+		return
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(artifact); err != nil {
+		m.options.logger.Error("could not write panic artifact", "file", f.Name(), "error", err)
+	}
+}
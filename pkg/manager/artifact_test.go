@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPanicArtifactDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "artifacts")
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithPanicArtifactDir(dir))
+
+	started := make(chan struct{})
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		close(started)
+
+		<-ctx.Done()
+	}, WithGoroutineName("waiter"))
+	<-started
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("panicker"), WithTags("io"))
+	m.Wait()
+
+	// Verify one artifact is written for the panic.
+	files, err := filepath.Glob(filepath.Join(dir, "panic-*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+
+	var artifact panicArtifact
+	require.NoError(t, json.Unmarshal(data, &artifact))
+
+	require.Equal(t, "panicker", artifact.Panic.Goroutine.Name)
+	require.Equal(t, testErr.Error(), artifact.Panic.Value)
+	require.NotEmpty(t, artifact.Panic.Stack)
+	require.Equal(t, []string{"io"}, artifact.Tags)
+
+	// Verify the snapshot includes the other running goroutine.
+	require.Equal(t, StateRunning.String(), artifact.Manager.State)
+	require.EqualValues(t, 2, artifact.Manager.Foreground.Running)
+
+	var names []string
+	for _, g := range artifact.Manager.Running {
+		names = append(names, g.Name)
+	}
+	require.ElementsMatch(t, []string{"waiter", "panicker"}, names)
+}
@@ -0,0 +1,57 @@
+package manager
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+var (
+	DefaultBackoff Backoff = ExponentialBackoff{Initial: 100 * time.Millisecond, Max: 30 * time.Second, Multiplier: 2, Jitter: 0.2} // Backoff used by supervised goroutines unless set with WithBackoff
+)
+
+// Backoff computes the delay before retrying or restarting after a failure
+type Backoff interface {
+	Delay(attempt int) time.Duration // Returns the delay before the attempt-th retry, starting at 1
+}
+
+// BackoffFunc is a function that implements Backoff
+type BackoffFunc func(attempt int) time.Duration
+
+func (f BackoffFunc) Delay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// ConstantBackoff returns a backoff that always waits for d
+func ConstantBackoff(d time.Duration) Backoff {
+	return BackoffFunc(func(int) time.Duration {
+		return d
+	})
+}
+
+// ExponentialBackoff is a backoff whose delay grows by Multiplier with each
+// attempt, starting at Initial and capped at Max
+type ExponentialBackoff struct {
+	Initial    time.Duration // Delay before the first retry
+	Max        time.Duration // Maximum delay; zero means no limit
+	Multiplier float64       // Factor to grow the delay by with each attempt; values below 1 are treated as 2
+	Jitter     float64       // Fraction of the delay, between 0 and 1, that is randomly subtracted to spread out retries
+}
+
+func (b ExponentialBackoff) Delay(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(max(attempt-1, 0)))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+
+	if jitter := min(max(b.Jitter, 0), 1); jitter > 0 {
+		delay -= delay * jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,37 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	t.Parallel()
+
+	backoff := ConstantBackoff(time.Second)
+	for attempt := 1; attempt <= 3; attempt++ {
+		require.Equal(t, time.Second, backoff.Delay(attempt))
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	backoff := ExponentialBackoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+
+	// Verify the delay grows exponentially until the maximum is reached.
+	require.Equal(t, time.Second, backoff.Delay(1))
+	require.Equal(t, 2*time.Second, backoff.Delay(2))
+	require.Equal(t, 4*time.Second, backoff.Delay(3))
+	require.Equal(t, 5*time.Second, backoff.Delay(4))
+
+	// Verify jitter only ever shortens the delay.
+	backoff.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := backoff.Delay(2)
+		require.LessOrEqual(t, delay, 2*time.Second)
+		require.GreaterOrEqual(t, delay, time.Second)
+	}
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrBarrierStopped = errors.New("barrier is stopped") // Returned by Await if the goroutine context was cancelled before the barrier was released
+)
+
+// Barrier synchronizes the phases of a fixed number of goroutines: each call
+// to Await blocks until n goroutines are waiting, then all of them are
+// released and the barrier can be used for the next phase. Create one with
+// NewBarrier.
+type Barrier struct {
+	m *GoroutineManager
+	n int
+
+	lock    sync.Mutex
+	waiting int
+	release chan struct{} // Closed once the current phase is complete
+}
+
+// NewBarrier creates a barrier for n goroutines. If the goroutine context is
+// cancelled, waiting goroutines are released early with ErrBarrierStopped, so
+// that workers waiting for each other don't deadlock the shutdown.
+func (m *GoroutineManager) NewBarrier(n int) *Barrier {
+	if n < 1 {
+		n = 1
+	}
+
+	return &Barrier{
+		m: m,
+		n: n,
+
+		release: make(chan struct{}),
+	}
+}
+
+// Await blocks until n goroutines are waiting for the barrier. It returns
+// ErrBarrierStopped if the goroutine context is cancelled first, or ctx's error
+// if ctx is done first, in which case the goroutine doesn't count towards the
+// current phase anymore.
+func (b *Barrier) Await(ctx context.Context) error {
+	if b.m.internalCtx.Err() != nil {
+		return ErrBarrierStopped
+	}
+
+	b.lock.Lock()
+	release := b.release
+	b.waiting++
+	if b.waiting == b.n {
+		close(release)
+
+		b.release = make(chan struct{})
+		b.waiting = 0
+	}
+	b.lock.Unlock()
+
+	// A completed phase takes precedence over stopping, since select picks a
+	// random case if several are ready
+	select {
+	case <-release:
+		return nil
+
+	default:
+	}
+
+	select {
+	case <-release:
+		return nil
+
+	case <-b.m.internalCtx.Done():
+		select {
+		case <-release:
+			return nil
+
+		default:
+			return ErrBarrierStopped
+		}
+
+	case <-ctx.Done():
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		// The phase may have completed concurrently
+		if b.release != release {
+			return nil
+		}
+		b.waiting--
+
+		// Goroutine contexts are done as well once the goroutine context is
+		// cancelled
+		if b.m.internalCtx.Err() != nil {
+			return ErrBarrierStopped
+		}
+
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBarrier(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	b := m.NewBarrier(3)
+
+	// Verify no goroutine starts a phase before all finished the previous one.
+	var arrived atomic.Int32
+	for range 3 {
+		m.StartForegroundGoroutine(func(ctx context.Context) {
+			for phase := range 3 {
+				arrived.Add(1)
+
+				require.NoError(t, b.Await(ctx))
+				require.GreaterOrEqual(t, arrived.Load(), int32(3*(phase+1)))
+			}
+		})
+	}
+	m.Wait()
+
+	require.NoError(t, errs)
+	require.Equal(t, int32(9), arrived.Load())
+}
+
+func TestBarrierStopped(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	b := m.NewBarrier(2)
+
+	awaited := make(chan error, 1)
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		awaited <- b.Await(ctx)
+	})
+
+	// Verify stopping the manager releases the waiting goroutine.
+	time.Sleep(10 * time.Millisecond)
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.ErrorIs(t, <-awaited, ErrBarrierStopped)
+	require.ErrorIs(t, b.Await(context.Background()), ErrBarrierStopped)
+}
+
+func TestBarrierStoppedAfterRelease(t *testing.T) {
+	t.Parallel()
+
+	for range 20 {
+		var errs error
+		m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+		b := m.NewBarrier(1)
+		phase := b.release
+
+		// Stop the manager while the goroutine is about to complete the phase
+		b.lock.Lock()
+		awaited := make(chan error, 1)
+		go func() {
+			awaited <- b.Await(context.Background())
+		}()
+		time.Sleep(time.Millisecond)
+
+		m.StopAllGoroutines()
+		b.lock.Unlock()
+		err := <-awaited
+
+		// Verify the goroutine isn't told the barrier stopped if it completed the
+		// phase.
+		b.lock.Lock()
+		completed := b.release != phase
+		b.lock.Unlock()
+
+		if completed {
+			require.NoError(t, err)
+		}
+
+		m.Wait()
+		require.NoError(t, errs)
+	}
+}
+
+func TestBarrierContextDone(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	b := m.NewBarrier(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, b.Await(ctx), context.DeadlineExceeded)
+
+	// Verify the goroutine that gave up doesn't count towards the phase.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		require.NoError(t, b.Await(context.Background()))
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("barrier released with one goroutine")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	require.NoError(t, b.Await(context.Background()))
+	<-done
+}
@@ -0,0 +1,124 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrChanClosed = errors.New("managed channel is closed") // Returned if a value is sent to a closed managed channel, or received from a closed and drained one
+	ErrChanFull   = errors.New("managed channel is full")   // Returned by TrySend if the channel's buffer is full
+)
+
+// ManagedChan is a channel that is closed once the goroutine context is
+// cancelled. Sending to it after it is closed returns ErrChanClosed instead
+// of panicking, so goroutines that produce values don't have to coordinate
+// with the consumers during shutdown. Create one with NewManagedChan.
+type ManagedChan[T any] struct {
+	ch   chan T
+	done chan struct{} // Closed once Close() is called, before ch is closed
+
+	lock      sync.RWMutex // Held for reading by senders, so that ch is only closed once no send is in progress
+	closeOnce sync.Once
+}
+
+// NewManagedChan creates a channel with a buffer of size capacity that is
+// closed once m's goroutine context is cancelled
+func NewManagedChan[T any](m *GoroutineManager, capacity int) *ManagedChan[T] {
+	c := &ManagedChan[T]{
+		ch:   make(chan T, capacity),
+		done: make(chan struct{}),
+	}
+
+	context.AfterFunc(m.internalCtx, c.Close)
+
+	return c
+}
+
+// Send sends v, blocking while the buffer is full. It returns ErrChanClosed if
+// the channel is closed first, or ctx's error if ctx is done first.
+func (c *ManagedChan[T]) Send(ctx context.Context, v T) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	select {
+	case <-c.done:
+		return ErrChanClosed
+	default:
+	}
+
+	select {
+	case c.ch <- v:
+		return nil
+	case <-c.done:
+		return ErrChanClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TrySend sends v without blocking. It returns ErrChanFull if the buffer is
+// full and ErrChanClosed if the channel is closed.
+func (c *ManagedChan[T]) TrySend(v T) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	select {
+	case <-c.done:
+		return ErrChanClosed
+	default:
+	}
+
+	select {
+	case c.ch <- v:
+		return nil
+	default:
+		return ErrChanFull
+	}
+}
+
+// Receive receives a value, blocking until one is available. Values that are
+// buffered when the channel is closed can still be received; afterwards,
+// ErrChanClosed is returned. It returns ctx's error if ctx is done first.
+func (c *ManagedChan[T]) Receive(ctx context.Context) (T, error) {
+	select {
+	case v, ok := <-c.ch:
+		if !ok {
+			return v, ErrChanClosed
+		}
+
+		return v, nil
+	case <-ctx.Done():
+		var zero T
+
+		return zero, ctx.Err()
+	}
+}
+
+// Chan returns the receive side of the channel, e.g. to range over it or to
+// select on it together with other channels
+func (c *ManagedChan[T]) Chan() <-chan T {
+	return c.ch
+}
+
+// Done returns a channel that is closed once the channel is closed, which
+// happens before the values buffered in it are drained
+func (c *ManagedChan[T]) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close closes the channel before the goroutine context is cancelled, e.g.
+// once a producer finished. Blocked senders return ErrChanClosed. It is safe
+// to call Close multiple times and concurrently with sends.
+func (c *ManagedChan[T]) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		// Wait for in-flight sends to observe done
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		close(c.ch)
+	})
+}
@@ -0,0 +1,101 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagedChan(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	c := NewManagedChan[int](m, 1)
+
+	require.NoError(t, c.Send(context.Background(), 1))
+	require.ErrorIs(t, c.TrySend(2), ErrChanFull)
+
+	v, err := c.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	// Verify ctx bounds blocking calls.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Receive(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, c.TrySend(2))
+	require.ErrorIs(t, c.Send(ctx, 3), context.DeadlineExceeded)
+}
+
+func TestManagedChanStopped(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	c := NewManagedChan[int](m, 1)
+
+	sent := make(chan error, 1)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		for i := 0; ; i++ {
+			if err := c.Send(context.Background(), i); err != nil {
+				sent <- err
+
+				return
+			}
+		}
+	})
+
+	v, err := c.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, v)
+
+	// Verify stopping the manager releases the blocked sender instead of
+	// letting it panic.
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.ErrorIs(t, <-sent, ErrChanClosed)
+	<-c.Done()
+
+	// Verify buffered values are drained before the channel reports that it's
+	// closed.
+	for {
+		if _, err := c.Receive(context.Background()); err != nil {
+			require.ErrorIs(t, err, ErrChanClosed)
+
+			break
+		}
+	}
+
+	require.ErrorIs(t, c.TrySend(1), ErrChanClosed)
+	require.NoError(t, errs)
+}
+
+func TestManagedChanClose(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	c := NewManagedChan[string](m, 2)
+	require.NoError(t, c.Send(context.Background(), "a"))
+	c.Close()
+	c.Close()
+
+	var received []string
+	for v := range c.Chan() {
+		received = append(received, v)
+	}
+	require.Equal(t, []string{"a"}, received)
+
+	require.ErrorIs(t, c.Send(context.Background(), "b"), ErrChanClosed)
+	require.NoError(t, m.Context().Err())
+}
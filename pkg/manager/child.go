@@ -0,0 +1,108 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// children holds the child managers of a goroutine manager in the order they
+// were created
+type children struct {
+	lock sync.Mutex
+	list []*GoroutineManager
+}
+
+// add registers a child manager
+func (c *children) add(child *GoroutineManager) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.list = append(c.list, child)
+}
+
+// ordered returns the child managers in the order they were created
+func (c *children) ordered() []*GoroutineManager {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]*GoroutineManager{}, c.list...)
+}
+
+// reversed returns the child managers, most recently created first
+func (c *children) reversed() []*GoroutineManager {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	out := make([]*GoroutineManager, 0, len(c.list))
+	for i := len(c.list) - 1; i >= 0; i-- {
+		out = append(out, c.list[i])
+	}
+
+	return out
+}
+
+// NewChild creates a goroutine manager for a subsystem, whose goroutine
+// context carries the values of m's goroutine context. Stopping m with
+// StopAllGoroutines() or StopInPriorityOrder() stops the child first, and a
+// panic in m or cancellation of m's parent context stops the child like
+// StopAllGoroutines() does. name is the child's name like WithName, e.g. in
+// the errors propagated with WithPanicPropagation.
+//
+// Children are kept for the lifetime of m, so they should be long-lived, e.g.
+// one per subsystem rather than one per request.
+func (m *GoroutineManager) NewChild(
+	name string, // Name of the child
+
+	errs *error, // An error variable to collect panics and errors into
+
+	hooks GoroutineManagerHooks, // Lifecycle hooks
+
+	opts ...GoroutineManagerOption, // Additional options
+) *GoroutineManager {
+	// The child's context isn't derived directly, so that its goroutines are
+	// stopped with its own cause instead of the parent's
+	child := NewGoroutineManager(context.WithoutCancel(m.internalCtx), errs, hooks, append(opts[:len(opts):len(opts)], WithName(name))...)
+	child.parent = m
+
+	m.children.add(child)
+	context.AfterFunc(m.internalCtx, child.StopAllGoroutines)
+
+	return child
+}
+
+// Parent returns the manager that created m with NewChild, or nil for a root
+// manager
+func (m *GoroutineManager) Parent() *GoroutineManager {
+	return m.parent
+}
+
+// Name returns the name of the goroutine manager set with WithName or
+// NewChild, or "" if it has none
+func (m *GoroutineManager) Name() string {
+	return m.name
+}
+
+// WaitTree waits like Wait() for m and then for all of its descendants created
+// with NewChild, so that `defer m.WaitTree()` in main() covers the whole
+// process. Children created while waiting are included.
+func (m *GoroutineManager) WaitTree() {
+	m.Wait()
+
+	for _, child := range m.children.ordered() {
+		child.WaitTree()
+	}
+}
+
+// propagatePanic adds an error recovered from a panic in a child or one of
+// its descendants to the errors of m, wrapped with the child's name, and
+// propagates it further if m propagates panics itself
+func (m *GoroutineManager) propagatePanic(child string, err error) {
+	err = fmt.Errorf("child goroutine manager %q: %w", child, err)
+
+	m.collectError(err)
+
+	if m.parent != nil && m.options.propagatePanics {
+		m.parent.propagatePanic(m.name, err)
+	}
+}
@@ -0,0 +1,178 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewChild(t *testing.T) {
+	t.Parallel()
+
+	var parentErrs error
+	parent := NewGoroutineManager(context.Background(), &parentErrs, GoroutineManagerHooks{})
+
+	var childErrs error
+	child := parent.NewChild("db", &childErrs, GoroutineManagerHooks{})
+
+	require.Same(t, parent, child.Parent())
+	require.Equal(t, "db", child.Name())
+	require.Nil(t, parent.Parent())
+
+	// Verify a panic in the parent stops the child's goroutines.
+	child.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	parent.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+
+	child.Wait()
+	parent.Wait()
+
+	require.ErrorIs(t, parentErrs, testErr)
+	require.NoError(t, childErrs)
+	require.ErrorIs(t, context.Cause(child.Context()), child.GetErrGoroutineStopped())
+}
+
+func TestStopAllGoroutinesChildren(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	root := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	a := root.NewChild("a", &errs, GoroutineManagerHooks{})
+	aa := a.NewChild("aa", &errs, GoroutineManagerHooks{})
+	b := root.NewChild("b", &errs, GoroutineManagerHooks{})
+
+	root.StopAllGoroutines()
+
+	// Verify the whole tree is stopped synchronously, with each manager's own
+	// cause.
+	for _, m := range []*GoroutineManager{root, a, aa, b} {
+		require.ErrorIs(t, context.Cause(m.Context()), m.GetErrGoroutineStopped())
+	}
+
+	// Verify stopping a child doesn't stop its parent.
+	var otherErrs error
+	other := NewGoroutineManager(context.Background(), &otherErrs, GoroutineManagerHooks{})
+	other.NewChild("c", &otherErrs, GoroutineManagerHooks{}).StopAllGoroutines()
+
+	require.NoError(t, other.Context().Err())
+}
+
+func TestStopInPriorityOrderChildren(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	parent := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	child := parent.NewChild("child", &errs, GoroutineManagerHooks{})
+
+	var (
+		lock  sync.Mutex
+		order []string
+	)
+	stopped := func(name string) func(context.Context) {
+		return func(ctx context.Context) {
+			<-ctx.Done()
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			order = append(order, name)
+		}
+	}
+
+	parent.StartForegroundGoroutine(stopped("parent"), WithStopPriority(0))
+	child.StartForegroundGoroutine(stopped("child 1"), WithStopPriority(1))
+	child.StartForegroundGoroutine(stopped("child 0"), WithStopPriority(0))
+
+	require.NoError(t, parent.StopInPriorityOrder(context.Background()))
+	child.Wait()
+	parent.Wait()
+
+	// Verify the child is stopped in its own priority order before the parent.
+	require.Equal(t, []string{"child 0", "child 1", "parent"}, order)
+}
+
+func TestWithPanicPropagation(t *testing.T) {
+	t.Parallel()
+
+	var rootErrs error
+	root := NewGoroutineManager(context.Background(), &rootErrs, GoroutineManagerHooks{})
+
+	var childErrs error
+	child := root.NewChild("storage", &childErrs, GoroutineManagerHooks{}, WithPanicPropagation())
+
+	var grandchildErrs error
+	grandchild := child.NewChild("db", &grandchildErrs, GoroutineManagerHooks{}, WithPanicPropagation())
+
+	grandchild.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+	grandchild.Wait()
+
+	// Verify the panic is recorded at every level, wrapped with the names of
+	// the children it propagated through.
+	require.ErrorIs(t, grandchildErrs, testErr)
+
+	require.ErrorIs(t, childErrs, testErr)
+	require.ErrorContains(t, childErrs, `child goroutine manager "db"`)
+
+	require.ErrorIs(t, rootErrs, testErr)
+	require.ErrorContains(t, rootErrs, `child goroutine manager "storage": child goroutine manager "db"`)
+
+	var p *PanicError
+	require.ErrorAs(t, rootErrs, &p)
+
+	// Verify the parents' goroutines are not stopped.
+	require.NoError(t, root.Context().Err())
+	require.NoError(t, child.Context().Err())
+}
+
+func TestWithoutPanicPropagation(t *testing.T) {
+	t.Parallel()
+
+	var parentErrs error
+	parent := NewGoroutineManager(context.Background(), &parentErrs, GoroutineManagerHooks{})
+
+	var childErrs error
+	child := parent.NewChild("db", &childErrs, GoroutineManagerHooks{})
+
+	child.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+	child.Wait()
+
+	require.ErrorIs(t, childErrs, testErr)
+	require.NoError(t, parentErrs)
+}
+
+func TestWaitTree(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	parent := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	child := parent.NewChild("child", &errs, GoroutineManagerHooks{})
+	grandchild := child.NewChild("grandchild", &errs, GoroutineManagerHooks{})
+
+	var finished atomic.Bool
+	grandchild.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+
+		time.Sleep(10 * time.Millisecond)
+
+		finished.Store(true)
+	})
+
+	parent.StopAllGoroutines()
+
+	// Verify waiting for the parent waits for the goroutines of its descendants.
+	parent.WaitTree()
+	require.True(t, finished.Load())
+}
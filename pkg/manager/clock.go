@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Clock provides the current time and timers to the goroutine manager. All
+// time-based features, e.g. slow goroutine detection, timeouts and periodic
+// goroutines, use it, so that they can be tested without sleeping by using a
+// fake clock like managertest.FakeClock.
+type Clock interface {
+	Now() time.Time                            // Returns the current time
+	NewTimer(d time.Duration) Timer            // Creates a timer that sends the current time on its channel after d
+	AfterFunc(d time.Duration, f func()) Timer // Creates a timer that calls f in its own goroutine after d
+}
+
+// Timer is a timer created by a Clock
+type Timer interface {
+	C() <-chan time.Time        // Returns the channel the time is sent on; nil for timers created with AfterFunc
+	Stop() bool                 // Prevents the timer from firing, see time.Timer.Stop
+	Reset(d time.Duration) bool // Changes the timer to fire after d, see time.Timer.Reset
+}
+
+// realClock is the default clock, which uses the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// withDeadline returns a context that is cancelled at deadline according to
+// clock, like context.WithDeadline
+func withDeadline(clock Clock, ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+
+	if parentDeadline, ok := ctx.Deadline(); ok && parentDeadline.Before(deadline) {
+		return context.WithCancel(ctx)
+	}
+
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	timer := clock.AfterFunc(deadline.Sub(clock.Now()), func() {
+		cancel(context.DeadlineExceeded)
+	})
+
+	return &clockDeadlineCtx{cancelCtx, deadline}, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// clockDeadlineCtx is a context cancelled at a deadline of a fake clock
+type clockDeadlineCtx struct {
+	context.Context
+
+	deadline time.Time
+}
+
+func (c *clockDeadlineCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockDeadlineCtx) Err() error {
+	if err := c.Context.Err(); err != nil && errors.Is(context.Cause(c.Context), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+
+	return c.Context.Err()
+}
@@ -0,0 +1,36 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealClock(t *testing.T) {
+	t.Parallel()
+
+	var clock Clock = realClock{}
+
+	timer := clock.NewTimer(time.Millisecond)
+	<-timer.C()
+
+	fired := make(chan any)
+	clock.AfterFunc(time.Millisecond, func() {
+		close(fired)
+	})
+	<-fired
+
+	// Verify deadlines use the standard library's contexts.
+	deadline := clock.Now().Add(time.Millisecond)
+	ctx, cancel := withDeadline(clock, context.Background(), deadline)
+	defer cancel()
+
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	actual, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.True(t, actual.Equal(deadline))
+}
@@ -0,0 +1,143 @@
+package manager
+
+import (
+	"sync"
+	"time"
+)
+
+// CollectorHooks are the hooks called by a Collector
+type CollectorHooks struct {
+	OnPanic func(GoroutineInfo, *PanicError) // Called after a panic has been collected
+}
+
+// Collector converts panics to errors the same way a goroutine manager does,
+// without managing any goroutines itself. It is useful for libraries that
+// start their own goroutines but want to report panics consistently.
+type Collector struct {
+	lock  sync.Mutex
+	sink  ErrorSink
+	list  []error // Collected errors in the order they were collected
+	hooks CollectorHooks
+	clock Clock
+}
+
+// NewCollector creates a new collector that joins the errors caused by panics
+// into errs
+func NewCollector(
+	errs *error, // Error variable to add panics to
+
+	hooks CollectorHooks, // Hooks
+) *Collector {
+	return NewCollectorWithSink(JoinErrors(errs), hooks)
+}
+
+// NewCollectorWithSink creates a new collector that adds errors caused by
+// panics to sink
+func NewCollectorWithSink(
+	sink ErrorSink, // The sink to add panics and errors to
+
+	hooks CollectorHooks, // Hooks
+) *Collector {
+	return newCollector(sink, hooks, realClock{})
+}
+
+func newCollector(sink ErrorSink, hooks CollectorHooks, clock Clock) *Collector {
+	return &Collector{
+		sink:  sink,
+		hooks: hooks,
+		clock: clock,
+	}
+}
+
+// Add adds an error to the collected errors, so that a Collector can be used
+// as the ErrorSink of another collector or goroutine manager
+func (c *Collector) Add(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.list = append(c.list, err)
+
+	c.sink.Add(err)
+}
+
+// SetSink replaces the sink that errors are added to and returns the errors
+// collected since the collector was created or the sink was last replaced.
+// Errors() only returns the errors collected after the replacement.
+func (c *Collector) SetSink(sink ErrorSink) []error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	list := c.list
+
+	c.sink = sink
+	c.list = nil
+
+	return list
+}
+
+// Errors returns the errors collected so far in the order they were collected
+func (c *Collector) Errors() []error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]error(nil), c.list...)
+}
+
+// Recover recovers the last panic and adds it to the collected errors as a
+// PanicError with the given name. It must be called directly from a defer
+// statement, otherwise recover() returns nil:
+//
+//	defer c.Recover("worker")
+func (c *Collector) Recover(name string) {
+	if err := recover(); err != nil {
+		now := c.clock.Now()
+
+		c.collect(GoroutineInfo{Name: name, Started: now}, err, now)
+	}
+}
+
+// Wraps fn so that a panic in it is recovered and collected as a PanicError
+// with the given name, e.g. to pass it to the go statement
+func (c *Collector) Wrap(name string, fn func()) func() {
+	return func() {
+		started := c.clock.Now()
+
+		defer func() {
+			if err := recover(); err != nil {
+				now := c.clock.Now()
+
+				c.collect(GoroutineInfo{Name: name, Started: started, Runtime: now.Sub(started)}, err, now)
+			}
+		}()
+
+		fn()
+	}
+}
+
+// collect adds a recovered panic value to the collected errors and calls the
+// OnPanic hook
+func (c *Collector) collect(info GoroutineInfo, value any, now time.Time) {
+	e := newPanicError(info, value, now)
+
+	c.Add(e)
+
+	if hook := c.hooks.OnPanic; hook != nil {
+		c.callHook("OnPanic", func() {
+			hook(info, e)
+		})
+	}
+}
+
+// callHook calls fn, which runs a hook, and collects a panic raised by it as
+// an error instead of letting it escape the recovery of another panic
+func (c *Collector) callHook(name string, fn func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			now := c.clock.Now()
+
+			c.Add(newPanicError(GoroutineInfo{Name: "hook " + name, Started: now}, err, now))
+		}
+	}()
+
+	fn()
+}
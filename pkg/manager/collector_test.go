@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectorRecover(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	c := NewCollector(&errs, CollectorHooks{})
+
+	func() {
+		defer c.Recover("worker")
+
+		panic(testErr)
+	}()
+
+	// Verify the panic is collected as a named PanicError.
+	require.ErrorIs(t, errs, testErr)
+
+	var e *PanicError
+	require.ErrorAs(t, errs, &e)
+	require.Equal(t, "worker", e.Name)
+	require.NotEmpty(t, e.Stack)
+
+	require.Len(t, c.Errors(), 1)
+}
+
+func TestCollectorWrap(t *testing.T) {
+	t.Parallel()
+
+	panics := make(chan *PanicError, 1)
+	c := NewCollectorWithSink(ErrorSinkFunc(func(err error) {}), CollectorHooks{
+		OnPanic: func(info GoroutineInfo, e *PanicError) {
+			panics <- e
+		},
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go c.Wrap("wrapped", func() {
+		defer wg.Done()
+
+		panic("test")
+	})()
+
+	wg.Wait()
+
+	// Verify the hook is called with the recovered panic.
+	e := <-panics
+	require.Equal(t, "wrapped", e.Name)
+	require.Equal(t, "test", e.Value)
+	require.Equal(t, []error{e}, c.Errors())
+}
+
+func TestCollectorHookPanic(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	c := NewCollector(&errs, CollectorHooks{
+		OnPanic: func(GoroutineInfo, *PanicError) {
+			panic(testErr)
+		},
+	})
+
+	func() {
+		defer c.Recover("worker")
+
+		panic(errors.New("first"))
+	}()
+
+	// Verify the panic in the hook is collected too.
+	require.Len(t, c.Errors(), 2)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestCollectorSetSink(t *testing.T) {
+	t.Parallel()
+
+	var first, second error
+	c := NewCollector(&first, CollectorHooks{})
+	c.Add(testErr)
+
+	// Verify the sink is replaced and the previous errors are returned.
+	require.Equal(t, []error{testErr}, c.SetSink(&joinedErrors{errs: &second}))
+	require.Empty(t, c.Errors())
+
+	c.Add(errors.New("second"))
+	require.ErrorIs(t, first, testErr)
+	require.NotContains(t, first.Error(), "second")
+	require.EqualError(t, second, "second")
+}
@@ -0,0 +1,141 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultCommandKillTimeout is how long a managed command has to exit after
+// the stop signal before it is killed, unless set with WithKillTimeout
+const DefaultCommandKillTimeout = 10 * time.Second
+
+// CommandOption configures a command managed with ManageCommand
+type CommandOption func(*commandOptions)
+
+type commandOptions struct {
+	startOptions []StartOption
+	stopSignal   os.Signal
+	killTimeout  time.Duration
+}
+
+// WithCommandStartOptions sets the options for the goroutine that waits for a
+// managed command, e.g. its name or stop priority
+func WithCommandStartOptions(opts ...StartOption) CommandOption {
+	return func(o *commandOptions) {
+		o.startOptions = append(o.startOptions, opts...)
+	}
+}
+
+// WithStopSignal sets the signal that is sent to a managed command when the
+// goroutine manager stops, instead of SIGTERM
+func WithStopSignal(sig os.Signal) CommandOption {
+	return func(o *commandOptions) {
+		o.stopSignal = sig
+	}
+}
+
+// WithKillTimeout sets how long a managed command has to exit after the stop
+// signal before it is killed, overriding DefaultCommandKillTimeout
+func WithKillTimeout(timeout time.Duration) CommandOption {
+	return func(o *commandOptions) {
+		o.killTimeout = timeout
+	}
+}
+
+// ManageCommand starts cmd and waits for it in a foreground goroutine. Once the
+// goroutine's context is cancelled, the process is sent SIGTERM, and killed if
+// it hasn't exited after the kill timeout. If the command exits with an error
+// before it is stopped, or has to be killed, the error is collected. If the
+// stop signal can't be sent, e.g. because the platform doesn't support it, the
+// process is killed right away. In dry-run mode, see WithDryRun, the command
+// isn't started and only the goroutine waiting for it is recorded.
+//
+// It returns an error if the command can't be started.
+func (m *GoroutineManager) ManageCommand(cmd *exec.Cmd, opts ...CommandOption) error {
+	options := commandOptions{
+		stopSignal:  syscall.SIGTERM,
+		killTimeout: DefaultCommandKillTimeout,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if m.dryRun == nil {
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("could not start command %v: %w", cmd.Path, err)
+		}
+	}
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		var (
+			lock         sync.Mutex
+			exited       bool
+			killed       bool // Killed after not exiting within the kill timeout
+			signalFailed bool // Killed because the stop signal couldn't be sent
+			killTimer    Timer
+		)
+
+		stop := context.AfterFunc(ctx, func() {
+			lock.Lock()
+			defer lock.Unlock()
+
+			if exited {
+				return
+			}
+
+			if err := cmd.Process.Signal(options.stopSignal); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				// Not all platforms support signals other than kill
+				m.options.logger.Warn("could not signal command, killing it", "command", cmd.Path, "error", err)
+
+				signalFailed = true
+				_ = cmd.Process.Kill()
+
+				return
+			}
+
+			killTimer = m.options.clock.AfterFunc(options.killTimeout, func() {
+				lock.Lock()
+				defer lock.Unlock()
+
+				if exited {
+					return
+				}
+
+				m.options.logger.Warn("command didn't exit in time, killing it", "command", cmd.Path, "timeout", options.killTimeout)
+
+				killed = true
+				_ = cmd.Process.Kill()
+			})
+		})
+		defer stop()
+
+		err := cmd.Wait()
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		exited = true
+		if killTimer != nil {
+			killTimer.Stop()
+		}
+
+		switch {
+		case signalFailed:
+			m.collectError(fmt.Errorf("command %v was killed after it could not be signalled: %w", cmd.Path, err))
+
+		case killed:
+			m.collectError(fmt.Errorf("command %v was killed after not exiting in time: %w", cmd.Path, err))
+
+		case err != nil && ctx.Err() == nil:
+			m.collectError(fmt.Errorf("command %v failed: %w", cmd.Path, err))
+		}
+	}, options.startOptions...)
+
+	return nil
+}
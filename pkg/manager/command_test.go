@@ -0,0 +1,158 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageCommand(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.NoError(t, m.ManageCommand(exec.Command("sh", "-c", "exit 0")))
+	m.Wait()
+
+	require.NoError(t, errs)
+}
+
+func TestManageCommandFailed(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.NoError(t, m.ManageCommand(exec.Command("sh", "-c", "exit 3")))
+	m.Wait()
+
+	// Verify the non-zero exit is collected.
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, errs, &exitErr)
+	require.Equal(t, 3, exitErr.ExitCode())
+}
+
+func TestManageCommandNotStarted(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.Error(t, m.ManageCommand(exec.Command("goroutine-manager-does-not-exist")))
+	m.Wait()
+
+	require.NoError(t, errs)
+}
+
+func TestManageCommandStopped(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX signals")
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.NoError(t, m.ManageCommand(exec.Command("sleep", "60")))
+
+	// Verify stopping the manager terminates the command without an error.
+	started := time.Now()
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.Less(t, time.Since(started), 10*time.Second)
+	require.NoError(t, errs)
+}
+
+func TestManageCommandKilled(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX signals")
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// The command ignores SIGTERM once it printed "ready"
+	cmd := exec.Command("sh", "-c", `trap "" TERM; echo ready; exec sleep 60`)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+
+	require.NoError(t, m.ManageCommand(cmd, WithKillTimeout(50*time.Millisecond), WithCommandStartOptions(WithGoroutineName("sleeper"))))
+
+	buf := make([]byte, len("ready\n"))
+	_, err = stdout.Read(buf)
+	require.NoError(t, err)
+
+	// Verify a command that ignores the stop signal is killed.
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.ErrorContains(t, errs, "was killed after not exiting in time")
+}
+
+// unsupportedSignal is a signal that os.Process.Signal can't send
+type unsupportedSignal struct{}
+
+func (unsupportedSignal) String() string {
+	return "unsupported"
+}
+
+func (unsupportedSignal) Signal() {}
+
+var _ os.Signal = unsupportedSignal{}
+
+func TestManageCommandSignalFailed(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX signals")
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.NoError(t, m.ManageCommand(exec.Command("sleep", "60"), WithStopSignal(unsupportedSignal{}), WithKillTimeout(time.Hour)))
+
+	// Verify a command that can't be signalled is killed right away, and the
+	// error doesn't blame the kill timeout.
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.ErrorContains(t, errs, "was killed after it could not be signalled")
+	require.NotContains(t, errs.Error(), "not exiting in time")
+}
+
+func TestManageCommandDryRun(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithDryRun())
+
+	// Verify the command isn't started, so even one that doesn't exist is only
+	// recorded.
+	cmd := exec.Command("goroutine-manager-does-not-exist")
+	require.NoError(t, m.ManageCommand(cmd, WithCommandStartOptions(WithGoroutineName("command"))))
+	m.Wait()
+
+	require.Nil(t, cmd.Process)
+	require.Len(t, m.Launches(), 1)
+	require.Equal(t, "command", m.Launches()[0].Name)
+	require.NoError(t, errs)
+}
@@ -0,0 +1,60 @@
+package manager
+
+import "context"
+
+type managerKey struct{}
+
+// WithManager returns a copy of ctx that carries m, so that deeply nested code
+// can start managed goroutines with FromContext() without threading m through
+// every constructor. Goroutine contexts carry their manager already.
+func WithManager(ctx context.Context, m *GoroutineManager) context.Context {
+	return context.WithValue(ctx, managerKey{}, m)
+}
+
+// FromContext returns the goroutine manager carried by ctx, see WithManager()
+func FromContext(ctx context.Context) (*GoroutineManager, bool) {
+	m, ok := ctx.Value(managerKey{}).(*GoroutineManager)
+
+	return m, ok
+}
+
+type goroutineIDKey struct{}
+
+// GoroutineIDFromContext returns the ID of the managed goroutine whose context
+// is ctx or derived from it. IDs are unique within a goroutine manager and
+// increase monotonically in the order the goroutines are started, so that logs
+// from the same goroutine can be correlated. They are also included in
+// GoroutineInfo and PanicError.
+func GoroutineIDFromContext(ctx context.Context) (uint64, bool) {
+	id, ok := ctx.Value(goroutineIDKey{}).(uint64)
+
+	return id, ok
+}
+
+type loggerKey struct{}
+
+type statsRecorderKey struct{}
+
+// LoggerFromContext returns the logger of the goroutine whose context is ctx
+// or derived from it, which adds the goroutine's name and ID to each message,
+// if the goroutine manager was created with WithGoroutineTelemetry. Otherwise,
+// it returns a logger that discards all messages.
+func LoggerFromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return logger
+	}
+
+	return nopLogger{}
+}
+
+// StatsRecorderFromContext returns the stats recorder of the goroutine whose
+// context is ctx or derived from it, which adds LabelGoroutine to all metrics,
+// if the goroutine manager was created with WithGoroutineTelemetry. Otherwise,
+// it returns a recorder that discards all metrics.
+func StatsRecorderFromContext(ctx context.Context) StatsRecorder {
+	if recorder, ok := ctx.Value(statsRecorderKey{}).(StatsRecorder); ok {
+		return recorder
+	}
+
+	return nopStatsRecorder{}
+}
@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	actual, ok := FromContext(WithManager(context.Background(), m))
+	require.True(t, ok)
+	require.Equal(t, m, actual)
+
+	// Verify goroutine contexts carry their manager.
+	var fromGoroutine *GoroutineManager
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		fromGoroutine, _ = FromContext(ctx)
+	})
+	m.Wait()
+	require.Same(t, m, fromGoroutine)
+}
+
+func TestGoroutineIDFromContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := GoroutineIDFromContext(context.Background())
+	require.False(t, ok)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSynchronous())
+
+	// Verify IDs increase in the order the goroutines are started.
+	var ids []uint64
+	for range 3 {
+		m.StartForegroundGoroutine(func(ctx context.Context) {
+			id, ok := GoroutineIDFromContext(ctx)
+			require.True(t, ok)
+
+			ids = append(ids, id)
+		})
+	}
+	require.Equal(t, []uint64{1, 2, 3}, ids)
+
+	// Verify the ID is included in panic errors.
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	var p *PanicError
+	require.ErrorAs(t, errs, &p)
+	require.Equal(t, uint64(4), p.ID)
+}
+
+func TestWithGoroutineTelemetry(t *testing.T) {
+	t.Parallel()
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	r := newTestRecorder()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithLogger(logger), WithStatsRecorder(r), WithName("scheduler"), WithGoroutineTelemetry())
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		LoggerFromContext(ctx).Info("processed", "items", 3)
+		StatsRecorderFromContext(ctx).AddCounter("items_total", 3, nil)
+	}, WithGoroutineName("worker"))
+	m.Wait()
+
+	// Verify the goroutine's logs and metrics carry its name and ID.
+	require.Contains(t, buf.String(), `msg=processed manager=scheduler goroutine=worker id=1 items=3`)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	require.Equal(t, float64(3), r.counters["items_total{schedulerworker}"])
+	require.NoError(t, errs)
+}
+
+func TestWithoutGoroutineTelemetry(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify the logger and recorder discard everything by default.
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		require.Equal(t, nopLogger{}, LoggerFromContext(ctx))
+		require.Equal(t, nopStatsRecorder{}, StatsRecorderFromContext(ctx))
+	})
+	m.Wait()
+
+	require.Equal(t, nopLogger{}, LoggerFromContext(context.Background()))
+	require.NoError(t, errs)
+}
@@ -0,0 +1,133 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Debounce returns a trigger function. Calling it schedules a call of fn on a
+// background goroutine once d has passed without the trigger being called
+// again, which coalesces bursts of triggers into one call. Triggers after the
+// goroutine context is cancelled are ignored.
+func (m *GoroutineManager) Debounce(d time.Duration, fn func(context.Context), opts ...StartOption) func() {
+	var (
+		lock  sync.Mutex
+		timer Timer
+	)
+
+	run := m.serialize(fn, opts)
+
+	return func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		timer = m.options.clock.AfterFunc(d, run)
+	}
+}
+
+// Throttle returns a trigger function. Calling it calls fn on a background
+// goroutine at most once every d: the first trigger calls fn immediately and
+// triggers within d of the last call are coalesced into one call once d has
+// passed. Triggers after the goroutine context is cancelled are ignored.
+func (m *GoroutineManager) Throttle(d time.Duration, fn func(context.Context), opts ...StartOption) func() {
+	var (
+		lock  sync.Mutex
+		last  time.Time
+		timer Timer
+	)
+
+	clock := m.options.clock
+
+	run := m.serialize(fn, opts)
+
+	return func() {
+		lock.Lock()
+
+		// A trailing call is already scheduled
+		if timer != nil {
+			lock.Unlock()
+
+			return
+		}
+
+		if wait := d - clock.Now().Sub(last); wait > 0 {
+			timer = clock.AfterFunc(wait, func() {
+				lock.Lock()
+				timer = nil
+				last = clock.Now()
+				lock.Unlock()
+
+				run()
+			})
+			lock.Unlock()
+
+			return
+		}
+
+		last = clock.Now()
+		lock.Unlock()
+
+		// fn runs inline in synchronous mode, so the lock must not be held
+		run()
+	}
+}
+
+// serialize returns a function that calls fn on a background goroutine,
+// making sure that there is at most one call at a time. Calls requested while
+// fn is running are coalesced into one call after it returns.
+//
+// The lock is never held while starting the goroutine, since fn runs inline on
+// the calling goroutine in synchronous mode.
+func (m *GoroutineManager) serialize(fn func(context.Context), opts []StartOption) func() {
+	var (
+		lock             sync.Mutex
+		running, pending bool
+	)
+
+	// Must be called with running set
+	var start func()
+	start = func() {
+		if m.internalCtx.Err() != nil {
+			lock.Lock()
+			running = false
+			lock.Unlock()
+
+			return
+		}
+
+		m.StartBackgroundGoroutine(func(ctx context.Context) {
+			defer func() {
+				lock.Lock()
+				again := pending
+				pending = false
+				running = again
+				lock.Unlock()
+
+				if again {
+					start()
+				}
+			}()
+
+			fn(ctx)
+		}, opts...)
+	}
+
+	return func() {
+		lock.Lock()
+		if running {
+			pending = true
+			lock.Unlock()
+
+			return
+		}
+		running = true
+		lock.Unlock()
+
+		start()
+	}
+}
@@ -0,0 +1,193 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebounce(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var calls atomic.Int64
+	trigger := m.Debounce(20*time.Millisecond, func(_ context.Context) {
+		calls.Add(1)
+	})
+
+	// Verify a burst of triggers results in one call after the burst.
+	for i := 0; i < 10; i++ {
+		trigger()
+		time.Sleep(time.Millisecond)
+	}
+	require.Zero(t, calls.Load())
+
+	require.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	require.Never(t, func() bool {
+		return calls.Load() > 1
+	}, 50*time.Millisecond, time.Millisecond)
+
+	// Verify triggers after stopping are ignored.
+	m.StopAllGoroutines()
+	trigger()
+	require.Never(t, func() bool {
+		return calls.Load() > 1
+	}, 50*time.Millisecond, time.Millisecond)
+	require.NoError(t, errs)
+}
+
+func TestThrottle(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var calls atomic.Int64
+	trigger := m.Throttle(50*time.Millisecond, func(_ context.Context) {
+		calls.Add(1)
+	})
+
+	// Verify the first trigger results in an immediate call.
+	trigger()
+	require.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, 100*time.Millisecond, time.Millisecond)
+
+	// Verify triggers within the interval are coalesced into one trailing call.
+	for i := 0; i < 10; i++ {
+		trigger()
+	}
+	require.Equal(t, int64(1), calls.Load())
+
+	require.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	require.Never(t, func() bool {
+		return calls.Load() > 2
+	}, 100*time.Millisecond, time.Millisecond)
+	require.NoError(t, errs)
+}
+
+func TestDebounceSynchronous(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSynchronous())
+
+	var calls atomic.Int64
+	trigger := m.Debounce(time.Millisecond, func(_ context.Context) {
+		calls.Add(1)
+	})
+
+	// Verify fn running inline doesn't deadlock the trigger.
+	trigger()
+	require.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	trigger()
+	require.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, time.Millisecond)
+	require.NoError(t, errs)
+}
+
+func TestThrottleSynchronous(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSynchronous())
+
+	var calls atomic.Int64
+	var trigger func()
+	trigger = m.Throttle(time.Millisecond, func(_ context.Context) {
+		// Verify triggering from fn itself doesn't deadlock either.
+		if calls.Add(1) == 1 {
+			trigger()
+		}
+	})
+
+	// Verify the first call runs inline without deadlocking.
+	trigger()
+	require.GreaterOrEqual(t, calls.Load(), int64(1))
+
+	require.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, time.Millisecond)
+	require.NoError(t, errs)
+}
+
+func TestSerializeSynchronous(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSynchronous())
+
+	var (
+		calls atomic.Int64
+		run   func()
+	)
+	run = m.serialize(func(_ context.Context) {
+		// Verify calls requested while fn runs inline are coalesced into one call after it.
+		if calls.Add(1) == 1 {
+			run()
+			run()
+		}
+	}, nil)
+
+	run()
+	require.Equal(t, int64(2), calls.Load())
+	require.NoError(t, errs)
+}
+
+func TestSerialize(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		calls   atomic.Int64
+		running atomic.Int64
+	)
+	done := make(chan any)
+	run := m.serialize(func(_ context.Context) {
+		if running.Add(1) > 1 {
+			panic(testErr)
+		}
+		defer running.Add(-1)
+
+		calls.Add(1)
+
+		<-done
+	}, nil)
+
+	// Verify calls while fn is running are coalesced into one call after it.
+	run()
+	require.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		run()
+	}
+
+	close(done)
+	require.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	require.Never(t, func() bool {
+		return calls.Load() > 2
+	}, 50*time.Millisecond, time.Millisecond)
+	require.NoError(t, errs)
+}
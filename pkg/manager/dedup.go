@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type dedupKey struct{}
+
+// dedup remembers the IDs of the tasks that a supervised goroutine completed
+// within its dedup window
+type dedup struct {
+	lock sync.Mutex
+
+	clock  Clock
+	window time.Duration
+
+	completed map[string]time.Time
+	order     []string // IDs in the order they were completed
+}
+
+func newDedup(clock Clock, window time.Duration) *dedup {
+	return &dedup{
+		clock:  clock,
+		window: window,
+
+		completed: map[string]time.Time{},
+	}
+}
+
+// prune forgets the tasks that were completed before the window. It must be
+// called with the lock held.
+func (d *dedup) prune(now time.Time) {
+	for len(d.order) > 0 {
+		id := d.order[0]
+		if now.Sub(d.completed[id]) < d.window {
+			return
+		}
+
+		delete(d.completed, id)
+		d.order = d.order[1:]
+	}
+}
+
+func (d *dedup) isCompleted(id string) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.prune(d.clock.Now())
+
+	_, ok := d.completed[id]
+
+	return ok
+}
+
+func (d *dedup) complete(id string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.clock.Now()
+	d.prune(now)
+
+	if _, ok := d.completed[id]; !ok {
+		d.order = append(d.order, id)
+	}
+	d.completed[id] = now
+}
+
+// RunOnce calls fn unless the task identified by id was already completed
+// within the dedup window of the supervised goroutine that ctx belongs to, see
+// WithDedupWindow. A task is completed once fn returns without panicking, so a
+// restart after a panic doesn't run the tasks that were completed just before
+// it again. It returns true if fn was called. Without a dedup window, fn is
+// always called.
+func RunOnce(ctx context.Context, id string, fn func()) bool {
+	d, ok := ctx.Value(dedupKey{}).(*dedup)
+	if !ok {
+		fn()
+
+		return true
+	}
+
+	if d.isCompleted(id) {
+		return false
+	}
+
+	fn()
+
+	d.complete(id)
+
+	return true
+}
@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOnce(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		runs     = map[string]int{}
+		attempts int
+	)
+	m.StartSupervisedGoroutine(func(ctx context.Context) {
+		attempts++
+
+		for _, id := range []string{"a", "b", "c"} {
+			RunOnce(ctx, id, func() {
+				runs[id]++
+
+				// Crash after completing a task the first time
+				if id == "b" && attempts == 1 {
+					panic(testErr)
+				}
+			})
+		}
+	}, WithBackoff(ConstantBackoff(time.Millisecond)), WithDedupWindow(time.Hour))
+	m.Wait()
+
+	// Verify completed tasks aren't run again after the restart, but the task
+	// that panicked is.
+	require.Equal(t, 2, attempts)
+	require.Equal(t, map[string]int{"a": 1, "b": 2, "c": 1}, runs)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestRunOnceWithoutWindow(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	for range 2 {
+		require.True(t, RunOnce(context.Background(), "a", func() {
+			runs++
+		}))
+	}
+
+	// Verify tasks always run without a dedup window.
+	require.Equal(t, 2, runs)
+}
+
+func TestDedupWindow(t *testing.T) {
+	t.Parallel()
+
+	d := newDedup(realClock{}, 10*time.Millisecond)
+
+	d.complete("a")
+	require.True(t, d.isCompleted("a"))
+
+	// Verify tasks are forgotten once the window has passed.
+	time.Sleep(10 * time.Millisecond)
+	require.False(t, d.isCompleted("a"))
+	require.Empty(t, d.order)
+}
@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// packageDir is the directory of the package's source files, which is used to
+// find the call sites of start calls outside of the package
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+
+	return filepath.Dir(file)
+}()
+
+// Launch describes a goroutine that would have been started in dry-run mode
+type Launch struct {
+	Name       string    // Name of the goroutine, if set with WithGoroutineName
+	Tags       []string  // Tags of the goroutine, if set with WithTags
+	Foreground bool      // Whether the goroutine would block Wait()
+	CallSite   string    // Absolute path of the file and line of the call that started the goroutine, e.g. "/src/app/main.go:42"
+	Time       time.Time // Time at which the goroutine would have been started
+}
+
+// dryRun records the goroutines that would have been started
+type dryRun struct {
+	lock     sync.Mutex
+	launches []Launch
+}
+
+func (d *dryRun) record(g *goroutine) {
+	launch := Launch{
+		Name:       g.options.name,
+		Tags:       g.options.tags,
+		Foreground: g.foreground,
+		CallSite:   callSite(),
+		Time:       g.started,
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.launches = append(d.launches, launch)
+}
+
+// callSite returns the absolute path of the file and line of the first caller
+// outside of the package, not counting its tests
+func callSite() string {
+	callers := make([]uintptr, 64)
+	frames := runtime.CallersFrames(callers[:runtime.Callers(2, callers)])
+
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// Launches returns the goroutines that would have been started in dry-run
+// mode in the order they were started, see WithDryRun. It returns nil if the
+// manager is not in dry-run mode.
+func (m *GoroutineManager) Launches() []Launch {
+	if m.dryRun == nil {
+		return nil
+	}
+
+	m.dryRun.lock.Lock()
+	defer m.dryRun.lock.Unlock()
+
+	return append([]Launch{}, m.dryRun.launches...)
+}
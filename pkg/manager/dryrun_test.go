@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDryRun(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithDryRun())
+
+	ran := false
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		ran = true
+	}, WithGoroutineName("worker"), WithTags("io"))
+	m.StartPeriodicGoroutine(time.Second, func(_ context.Context) {
+		ran = true
+	}, WithGoroutineName("ticker"), WithBackground())
+
+	// Verify the goroutines are recorded without running.
+	m.Wait()
+	require.False(t, ran)
+
+	launches := m.Launches()
+	require.Len(t, launches, 2)
+
+	require.Equal(t, "worker", launches[0].Name)
+	require.Equal(t, []string{"io"}, launches[0].Tags)
+	require.True(t, launches[0].Foreground)
+
+	require.Equal(t, "ticker", launches[1].Name)
+	require.False(t, launches[1].Foreground)
+
+	// Verify the call sites point to the start calls, not the package.
+	for _, launch := range launches {
+		require.True(t, strings.HasPrefix(filepath.Base(launch.CallSite), "dryrun_test.go:"), launch.CallSite)
+		require.True(t, filepath.IsAbs(launch.CallSite), launch.CallSite)
+	}
+
+	require.NoError(t, errs)
+	require.Nil(t, NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}).Launches())
+}
@@ -0,0 +1,270 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+var (
+	ErrGoroutineOverran = errors.New("goroutine overran its deadline") // Collected if a goroutine started with WithOverrunError is still running at its deadline
+	ErrGoroutineStopped = errors.New("goroutine stopped")              // Wrapped by the context cause of every manager when its goroutines are stopped
+)
+
+// PanicError is an error that was recovered from a panic in a goroutine
+type PanicError struct {
+	Manager string        // Name of the goroutine manager, if set with WithName
+	ID      uint64        // ID of the goroutine, see GoroutineIDFromContext
+	Name    string        // Name of the goroutine, if set with WithGoroutineName
+	Value   any           // Value that was passed to panic()
+	Stack   []byte        // Stack trace of the goroutine at the time of the panic
+	Time    time.Time     // Time at which the panic was recovered
+	Started time.Time     // Time at which the goroutine (or panic collector) was started
+	Runtime time.Duration // How long the goroutine had been running when it panicked
+
+	err     error
+	callers []uintptr
+}
+
+// newPanicError creates a panic error for a value recovered at time t from a
+// goroutine with metadata info. It must be called from the function that
+// recovered, since the stack is captured from there.
+func newPanicError(info GoroutineInfo, value any, t time.Time) *PanicError {
+	var err error
+	if v, ok := value.(error); ok {
+		err = v
+	} else {
+		err = fmt.Errorf("%v", value)
+	}
+
+	callers := make([]uintptr, 64)
+	callers = callers[:runtime.Callers(2, callers)]
+
+	return &PanicError{
+		Manager: info.Manager,
+		ID:      info.ID,
+		Name:    info.Name,
+		Value:   value,
+		Stack:   debug.Stack(),
+		Time:    t,
+		Started: info.Started,
+		Runtime: info.Runtime,
+
+		err:     err,
+		callers: callers,
+	}
+}
+
+func (e *PanicError) Error() string {
+	if e.Name == "" {
+		return e.err.Error()
+	}
+
+	return e.Name + ": " + e.err.Error()
+}
+
+func (e *PanicError) Unwrap() error {
+	return e.err
+}
+
+// Frames returns the stack frames of the goroutine at the time of the panic,
+// starting with the function that panicked
+func (e *PanicError) Frames() []runtime.Frame {
+	if len(e.callers) == 0 {
+		return nil
+	}
+
+	var (
+		frames []runtime.Frame
+		iter   = runtime.CallersFrames(e.callers)
+	)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+
+		// Skip the frames of the recovery function and the runtime
+		if frame.Function == "runtime.gopanic" {
+			frames = frames[:0]
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return frames
+}
+
+// RecoverToError converts a value returned by recover() to a *PanicError the
+// same way a goroutine manager does, capturing the stack of the panicking
+// goroutine. It returns nil if recovered is nil, so it can be used as:
+//
+//	defer func() {
+//		if err := manager.RecoverToError(recover()); err != nil {
+//			...
+//		}
+//	}()
+func RecoverToError(recovered any) error {
+	if recovered == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	return newPanicError(GoroutineInfo{Started: now}, recovered, now)
+}
+
+// PanicsFrom walks the tree of joined and wrapped errors in err and returns
+// every panic error it contains, in the order they were collected
+func PanicsFrom(err error) []*PanicError {
+	var panics []*PanicError
+	if p, ok := err.(*PanicError); ok {
+		panics = append(panics, p)
+	}
+
+	switch v := err.(type) {
+	case interface{ Unwrap() error }:
+		panics = append(panics, PanicsFrom(v.Unwrap())...)
+
+	case interface{ Unwrap() []error }:
+		for _, e := range v.Unwrap() {
+			panics = append(panics, PanicsFrom(e)...)
+		}
+	}
+
+	return panics
+}
+
+// FormatErrors produces a human-readable report for err, with one section per
+// collected error. Sections for errors recovered from panics include the name
+// of the goroutine, the time of the panic, how long the goroutine had been
+// running and the stack trace.
+func FormatErrors(err error) string {
+	errs := flattenErrors(err)
+
+	var b strings.Builder
+	for i, e := range errs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(&b, "=== Error %d of %d ===\n", i+1, len(errs))
+
+		var p *PanicError
+		if !errors.As(e, &p) {
+			fmt.Fprintf(&b, "Message:   %v\n", e)
+
+			continue
+		}
+
+		name := p.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+
+		fmt.Fprintf(&b, "Goroutine: %v\n", name)
+		fmt.Fprintf(&b, "Time:      %v\n", p.Time.Format(time.RFC3339Nano))
+		fmt.Fprintf(&b, "Runtime:   %v\n", p.Runtime)
+		fmt.Fprintf(&b, "Message:   %v\n", e)
+		fmt.Fprintf(&b, "\n%s", p.Stack)
+	}
+
+	return b.String()
+}
+
+type jsonGoroutine struct {
+	Manager string    `json:"manager,omitempty"`
+	ID      uint64    `json:"id,omitempty"`
+	Name    string    `json:"name,omitempty"`
+	Started time.Time `json:"started"`
+	Runtime string    `json:"runtime"`
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+type jsonError struct {
+	Message string `json:"message"`
+
+	Goroutine *jsonGoroutine `json:"goroutine,omitempty"`
+	Value     string         `json:"value,omitempty"`
+	Type      string         `json:"type,omitempty"`
+	Time      *time.Time     `json:"time,omitempty"`
+	Stack     string         `json:"stack,omitempty"`
+	Frames    []jsonFrame    `json:"frames,omitempty"`
+}
+
+// ErrorsJSON serializes the errors collected in err into a JSON array, with
+// one object per collected error. Objects for errors recovered from panics
+// include the panic value, stack frames and goroutine metadata.
+func ErrorsJSON(err error) ([]byte, error) {
+	out := []jsonError{}
+	for _, e := range flattenErrors(err) {
+		out = append(out, newJSONError(e))
+	}
+
+	return json.Marshal(out)
+}
+
+// newJSONError converts a collected error to its JSON representation
+func newJSONError(e error) jsonError {
+	je := jsonError{
+		Message: e.Error(),
+	}
+
+	var p *PanicError
+	if errors.As(e, &p) {
+		je.Goroutine = &jsonGoroutine{
+			Manager: p.Manager,
+			ID:      p.ID,
+			Name:    p.Name,
+			Started: p.Started,
+			Runtime: p.Runtime.String(),
+		}
+		je.Value = fmt.Sprintf("%v", p.Value)
+		je.Type = fmt.Sprintf("%T", p.Value)
+		je.Time = &p.Time
+		je.Stack = string(p.Stack)
+
+		for _, frame := range p.Frames() {
+			je.Frames = append(je.Frames, jsonFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+	}
+
+	return je
+}
+
+// flattenErrors splits err into the errors that were joined into it, without
+// splitting up panic errors
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*PanicError); ok {
+		return []error{err}
+	}
+
+	v, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, e := range v.Unwrap() {
+		errs = append(errs, flattenErrors(e)...)
+	}
+
+	return errs
+}
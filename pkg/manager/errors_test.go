@@ -0,0 +1,227 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicsFrom(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("first"), WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic("test panic")
+	}, WithGoroutineName("second"), WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	// Verify the panics can be extracted from a wrapped error.
+	panics := PanicsFrom(fmt.Errorf("could not run: %w", errs))
+	require.Len(t, panics, 2)
+
+	require.Equal(t, "first", panics[0].Name)
+	require.Equal(t, testErr, panics[0].Value)
+	require.ErrorIs(t, panics[0], testErr)
+	require.Contains(t, string(panics[0].Stack), "TestPanicsFrom")
+	require.Equal(t, "first: test error", panics[0].Error())
+
+	require.Equal(t, "second", panics[1].Name)
+	require.Equal(t, "test panic", panics[1].Value)
+	require.Contains(t, string(panics[1].Stack), "TestPanicsFrom")
+	require.Equal(t, "second: test panic", panics[1].Error())
+}
+
+func TestPanicsFromNoPanics(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, PanicsFrom(nil))
+	require.Empty(t, PanicsFrom(errors.Join(testErr, errors.New("other error"))))
+}
+
+func TestFormatErrors(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("worker"), WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic("test panic")
+	}, WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	report := FormatErrors(errors.Join(errs, errors.New("other error")))
+
+	// Verify there is one section per error.
+	require.Equal(t, 3, strings.Count(report, "=== Error "))
+	require.Contains(t, report, "=== Error 1 of 3 ===\nGoroutine: worker\nTime:      ")
+	require.Contains(t, report, "Message:   worker: test error\n")
+	require.Contains(t, report, "Goroutine: (unnamed)\n")
+	require.Contains(t, report, "Message:   test panic\n")
+	require.Contains(t, report, "=== Error 3 of 3 ===\nMessage:   other error\n")
+	require.Contains(t, report, "TestFormatErrors")
+
+	require.Empty(t, FormatErrors(nil))
+}
+
+func TestErrorsJSON(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("worker"), WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	b, err := ErrorsJSON(errors.Join(errs, errors.New("other error")))
+	require.NoError(t, err)
+
+	var report []struct {
+		Message   string `json:"message"`
+		Goroutine *struct {
+			Name string `json:"name"`
+		} `json:"goroutine"`
+		Value  string     `json:"value"`
+		Type   string     `json:"type"`
+		Time   *time.Time `json:"time"`
+		Stack  string     `json:"stack"`
+		Frames []struct {
+			Function string `json:"function"`
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+		} `json:"frames"`
+	}
+	require.NoError(t, json.Unmarshal(b, &report))
+	require.Len(t, report, 2)
+
+	// Verify panics include their metadata.
+	require.Equal(t, "worker: test error", report[0].Message)
+	require.Equal(t, "worker", report[0].Goroutine.Name)
+	require.Equal(t, "test error", report[0].Value)
+	require.Equal(t, "*errors.errorString", report[0].Type)
+	require.NotNil(t, report[0].Time)
+	require.Contains(t, report[0].Stack, "TestErrorsJSON")
+
+	require.NotEmpty(t, report[0].Frames)
+	require.True(t, strings.HasSuffix(report[0].Frames[0].Function, "TestErrorsJSON.func1"))
+	require.True(t, strings.HasSuffix(report[0].Frames[0].File, "errors_test.go"))
+	require.NotZero(t, report[0].Frames[0].Line)
+
+	// Verify other errors only include their message.
+	require.Equal(t, "other error", report[1].Message)
+	require.Nil(t, report[1].Goroutine)
+	require.Empty(t, report[1].Frames)
+
+	// Verify no errors produce an empty array.
+	b, err = ErrorsJSON(nil)
+	require.NoError(t, err)
+	require.JSONEq(t, "[]", string(b))
+}
+
+func TestPanicErrorRuntime(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		time.Sleep(20 * time.Millisecond)
+
+		panic(testErr)
+	}, WithGoroutineName("worker"))
+	m.Wait()
+
+	// Verify the panic time and the goroutine's lifetime are recorded.
+	panics := PanicsFrom(errs)
+	require.Len(t, panics, 1)
+	require.GreaterOrEqual(t, panics[0].Runtime, 20*time.Millisecond)
+	require.Equal(t, panics[0].Runtime, panics[0].Time.Sub(panics[0].Started))
+
+	require.Contains(t, FormatErrors(errs), "Runtime:   "+panics[0].Runtime.String())
+
+	out, err := ErrorsJSON(errs)
+	require.NoError(t, err)
+
+	var decoded []jsonError
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	require.Len(t, decoded, 1)
+	require.Equal(t, panics[0].Runtime.String(), decoded[0].Goroutine.Runtime)
+	require.True(t, panics[0].Started.Equal(decoded[0].Goroutine.Started))
+}
+
+func TestRecoverToError(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, RecoverToError(nil))
+
+	var err error
+	func() {
+		defer func() {
+			err = RecoverToError(recover())
+		}()
+
+		panic(testErr)
+	}()
+
+	// Verify the panic is converted like in a managed goroutine.
+	require.ErrorIs(t, err, testErr)
+
+	var e *PanicError
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, testErr, e.Value)
+	require.NotEmpty(t, e.Stack)
+	require.NotEmpty(t, e.Frames())
+}
+
+func TestAllErrors(t *testing.T) {
+	t.Parallel()
+
+	errInitial := errors.New("initial error")
+
+	errs := errInitial
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.Empty(t, m.AllErrors())
+
+	expected := []error{}
+	for i := 0; i < 5; i++ {
+		err := fmt.Errorf("error %d", i)
+		expected = append(expected, err)
+
+		// Run the goroutines one after another so that the order is deterministic
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			panic(err)
+		}, WithPanicPolicy(PanicPolicyRecord))
+		m.Wait()
+	}
+
+	// Verify the errors are collected in order.
+	all := m.AllErrors()
+	require.Len(t, all, len(expected))
+	for i, err := range all {
+		require.ErrorIs(t, err, expected[i])
+	}
+
+	// Verify the joined error is flat and keeps the initial error first.
+	unwrapped := errs.(interface{ Unwrap() []error }).Unwrap()
+	require.Equal(t, append([]error{errInitial}, all...), unwrapped)
+}
@@ -4,27 +4,115 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// FatalHandler is called after recovering from a panic in a critical
+// goroutine if no OnFatal hook is set. By default it logs the error and exits
+// the process.
+var FatalHandler = func(err error) {
+	log.Printf("goroutine manager: critical goroutine failed: %v", err)
+
+	os.Exit(1)
+}
+
 // GoroutineManagerHooks allows hooking into the goroutine manager's lifecycle
 type GoroutineManagerHooks struct {
-	OnAfterRecover func() // Runs after recovering from a panic, but before stopping all goroutines
+	OnAfterRecover      func()                                    // Runs after recovering from a panic, but before stopping all goroutines
+	OnAfterRecoverError func() error                              // Like OnAfterRecover, but the returned error is collected into errs
+	OnPanic             func(info GoroutineInfo, err *PanicError) // Runs after recovering from a panic with the goroutine's metadata and the recovered error, e.g. to report it
+	OnFatal             func(err error)                           // Runs instead of FatalHandler after recovering from a panic in a critical goroutine
+	OnShutdown          func() error                              // Runs once the goroutine context is cancelled; the returned error is collected into errs
+	OnRuntimeSample     func(sample RuntimeSample)                // Runs with each sample taken by StartRuntimeMonitor()
+}
+
+// GoroutineInfo contains metadata about a goroutine
+type GoroutineInfo struct {
+	Manager string        // Name of the goroutine manager, if set with WithName
+	ID      uint64        // Unique, monotonically increasing ID of the goroutine within its goroutine manager, or 0 outside of managed goroutines
+	Name    string        // Name of the goroutine, if set with WithGoroutineName
+	Tags    []string      // Tags of the goroutine, if set with WithTags
+	Started time.Time     // Time at which the goroutine (or panic collector) was started
+	Runtime time.Duration // How long the goroutine had been running when the metadata was captured
+	Stack   []byte        // Current stack of the goroutine, only set by Blocking() if available
+}
+
+// goroutine holds the state of a goroutine or panic collector
+type goroutine struct {
+	options    startOptions
+	foreground bool
+	started    time.Time
+
+	ctx       context.Context // Goroutine context, or the context of its stop priority level
+	stopLevel *stopLevel
+
+	id        atomic.Uint64 // Runtime ID, if needed for stack dumps
+	slowTimer Timer
+	heartbeat *heartbeat
+	storage   *storage
+	managedID uint64 // Unique ID within the goroutine manager, see GoroutineIDFromContext
+	manager   string // Name of the goroutine manager
+	readiness *goroutineReadiness
+}
+
+// info returns the goroutine's metadata at time now
+func (g *goroutine) info(now time.Time) GoroutineInfo {
+	return GoroutineInfo{
+		Manager: g.manager,
+		ID:      g.managedID,
+		Name:    g.options.name,
+		Tags:    g.options.tags,
+		Started: g.started,
+		Runtime: now.Sub(g.started),
+	}
 }
 
 // GoroutineManager provides panic handling and lifecycle management for
 // goroutines.
 type GoroutineManager struct {
-	errs     *error
-	errsLock *sync.Mutex
-	wg       *sync.WaitGroup
+	errs *Collector
+	wg   *sync.WaitGroup
 
 	internalCtx       context.Context
 	cancelInternalCtx context.CancelCauseFunc
+	detachedCtx       context.Context // Like internalCtx, but not cancelled by a deadline of the parent context
 
 	errFinished error
 
-	hooks GoroutineManagerHooks
+	quiesced    chan struct{}
+	quiesceOnce *sync.Once
+
+	cleanups     *cleanups
+	shutdownDone chan struct{}
+
+	hooks   *atomic.Pointer[GoroutineManagerHooks] // Replaced by SetHooks()
+	options goroutineManagerOptions
+
+	stats     *stats
+	tracker   *tracker
+	lifecycle *lifecycle
+
+	restartBudget *restartBudget
+	health        *health
+	stopLevels    *stopLevels
+	tagLimits     *tagLimits
+	admission     *admission
+	dryRun        *dryRun
+	tracer        *tracer
+	nextID        atomic.Uint64
+	parent        *GoroutineManager
+	name          string
+	children      *children
+	once          *onceNames
+	slowWatchdog  *atomic.Pointer[slowWatchdog] // Replaced by SetSlowThreshold()
+	panicRate     *panicRate
+
+	shutdownDeadline *atomic.Pointer[time.Time] // Set by StopAllGoroutinesWithin()
+	readiness        *readiness
 }
 
 // NewGoroutineManager creates a new goroutine manager.
@@ -37,60 +125,262 @@ func NewGoroutineManager(
 	errs *error, // An error variable to collect panics and errors into
 
 	hooks GoroutineManagerHooks, // Lifecycle hooks
+
+	opts ...GoroutineManagerOption, // Additional options
+) *GoroutineManager {
+	return NewGoroutineManagerWithSink(ctx, JoinErrors(errs), hooks, opts...)
+}
+
+// NewGoroutineManagerWithSink creates a new goroutine manager that adds
+// errors caused by panics to sink instead of collecting them into one error
+// variable, e.g. to route them into a channel, a logger or metrics directly.
+func NewGoroutineManagerWithSink(
+	ctx context.Context, // Parent context to use
+
+	sink ErrorSink, // The sink to add panics and errors to
+
+	hooks GoroutineManagerHooks, // Lifecycle hooks
+
+	opts ...GoroutineManagerOption, // Additional options
 ) *GoroutineManager {
 	var (
-		errsLock sync.Mutex
-		wg       sync.WaitGroup
+		wg          sync.WaitGroup
+		quiesceOnce sync.Once
 	)
 
-	internalCtx, cancelInternalCtx := context.WithCancelCause(ctx)
+	quiesced := make(chan struct{})
+	shutdownDeadline := &atomic.Pointer[time.Time]{}
+
+	// The goroutine context carries the manager, which is filled in below
+	m := &GoroutineManager{}
+
+	internalCtx, cancelInternal := context.WithCancelCause(WithManager(context.WithValue(context.WithValue(ctx, shutdownSignalKey{}, quiesced), shutdownDeadlineKey{}, shutdownDeadline), m))
+	detachedCtx, cancelDetached := context.WithCancelCause(context.WithoutCancel(internalCtx))
+
+	cancelInternalCtx := func(cause error) {
+		cancelInternal(cause)
+		cancelDetached(cause)
+	}
+
+	context.AfterFunc(internalCtx, func() {
+		// A deadline of the parent context doesn't stop detached goroutines
+		if !errors.Is(internalCtx.Err(), context.DeadlineExceeded) {
+			cancelDetached(context.Cause(internalCtx))
+		}
+	})
 
-	errFinished := errors.New("finished") // This has to be a distinct error type for each panic handler, so we can't define it on the package level
+	options := newGoroutineManagerOptions(opts)
 
-	return &GoroutineManager{
-		errs,
-		&errsLock,
+	// This has to be a distinct error for each panic handler, so we can't use ErrGoroutineStopped directly
+	errFinished := fmt.Errorf("%w", ErrGoroutineStopped)
+	if options.stopError != nil {
+		errFinished = fmt.Errorf("%w: %w", ErrGoroutineStopped, options.stopError)
+	}
+
+	*m = GoroutineManager{
+		newCollector(sink, CollectorHooks{}, options.clock),
 		&wg,
 
 		internalCtx,
 		cancelInternalCtx,
+		detachedCtx,
 
 		errFinished,
 
-		hooks,
+		quiesced,
+		&quiesceOnce,
+
+		&cleanups{},
+		make(chan struct{}),
+
+		newAtomicPointer(hooks),
+		options,
+
+		newStats(options.statsRecorder, options.highWatermark, options.onHighWatermark),
+		newTracker(),
+		&lifecycle{},
+
+		nil,
+		newHealth(),
+		newStopLevels(),
+		newTagLimits(options.tagLimits),
+		nil,
+		nil,
+		nil,
+		atomic.Uint64{},
+		nil,
+		options.name,
+		&children{},
+		newOnceNames(),
+		newAtomicPointer(slowWatchdog{options.slowThreshold, options.onSlowGoroutine}),
+		nil,
+
+		shutdownDeadline,
+		newReadiness(),
+	}
+
+	if options.restartBurst > 0 && options.restartInterval > 0 {
+		m.restartBudget = &restartBudget{
+			burst:    options.restartBurst,
+			interval: options.restartInterval,
+		}
+	}
+
+	if options.dryRun {
+		m.dryRun = &dryRun{}
+	}
+
+	if options.traceWriter != nil {
+		m.tracer = newTracer(options.traceWriter, options.logger)
+	}
+
+	if options.panicRateWindow > 0 {
+		m.panicRate = &panicRate{
+			rate:   options.panicRate,
+			window: options.panicRateWindow,
+		}
 	}
+
+	if options.admissionPolicy != nil {
+		m.admission = &admission{
+			policy: *options.admissionPolicy,
+		}
+	}
+
+	// Cancelling the goroutine context implies that no new work should be accepted
+	context.AfterFunc(internalCtx, m.shutdown)
+
+	return m
 }
 
 // Creates a panic collector that can be waited for to finish
-func (m *GoroutineManager) CreateForegroundPanicCollector() func() {
+func (m *GoroutineManager) CreateForegroundPanicCollector(opts ...StartOption) func() {
 	m.wg.Add(1)
 
-	return m.recoverFromPanics(true)
+	g := m.startGoroutine(true, opts)
+	m.attachGoroutine(g)
+
+	return m.recoverFromPanics(g)
 }
 
 // Creates a panic collector that can't be waited for to finish
-func (m *GoroutineManager) CreateBackgroundPanicCollector() func() {
-	return m.recoverFromPanics(false)
+func (m *GoroutineManager) CreateBackgroundPanicCollector(opts ...StartOption) func() {
+	g := m.startGoroutine(false, opts)
+	m.attachGoroutine(g)
+
+	return m.recoverFromPanics(g)
+}
+
+// Wraps fn with a panic collector that can't be waited for to finish, so that
+// it can be handed to libraries that spawn their own goroutines or invoke
+// callbacks that we can't start ourselves
+func (m *GoroutineManager) Wrap(fn func(), opts ...StartOption) func() {
+	return func() {
+		defer m.CreateBackgroundPanicCollector(opts...)()
+
+		fn()
+	}
+}
+
+// Wraps fn with a panic collector that can't be waited for to finish, passing
+// through the context provided by the caller. See Wrap().
+func (m *GoroutineManager) WrapContext(fn func(context.Context), opts ...StartOption) func(context.Context) {
+	return func(ctx context.Context) {
+		defer m.CreateBackgroundPanicCollector(opts...)()
+
+		fn(ctx)
+	}
 }
 
 // Starts a goroutine that can be waited for to finish and associates a panic collector
-func (m *GoroutineManager) StartForegroundGoroutine(fn func(context.Context)) {
+func (m *GoroutineManager) StartForegroundGoroutine(fn func(context.Context), opts ...StartOption) {
 	m.wg.Add(1)
 
-	go func() {
-		defer m.recoverFromPanics(true)()
+	g := m.startGoroutine(true, opts)
+	m.startHeartbeat(g)
 
-		fn(m.internalCtx)
-	}()
+	m.spawn(g, fn)
 }
 
 // Starts a goroutine that can't be waited for to finish and associates a panic collector
-func (m *GoroutineManager) StartBackgroundGoroutine(fn func(context.Context)) {
-	go func() {
-		defer m.recoverFromPanics(false)()
+func (m *GoroutineManager) StartBackgroundGoroutine(fn func(context.Context), opts ...StartOption) {
+	g := m.startGoroutine(false, opts)
+	m.startHeartbeat(g)
 
-		fn(m.internalCtx)
-	}()
+	m.spawn(g, fn)
+}
+
+// Starts a goroutine that can be waited for to finish and whose failure is
+// considered fatal. This is a shorthand for starting a foreground goroutine
+// with the PanicPolicyFatal policy.
+func (m *GoroutineManager) StartCriticalGoroutine(fn func(context.Context), opts ...StartOption) {
+	m.StartForegroundGoroutine(fn, append(opts[:len(opts):len(opts)], WithPanicPolicy(PanicPolicyFatal))...)
+}
+
+// Starts a goroutine that can be waited for to finish, but whose context is
+// not cancelled when the goroutine context is cancelled, e.g. by
+// StopAllGoroutines(). This is useful for flushing and teardown work that must
+// run to completion during a shutdown. The context still carries the values of
+// the goroutine context.
+func (m *GoroutineManager) StartCleanupGoroutine(fn func(context.Context), opts ...StartOption) {
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		fn(context.WithoutCancel(ctx))
+	}, opts...)
+}
+
+// Starts a goroutine that can be waited for to finish and whose context is
+// cancelled at deadline or when the goroutine context is cancelled, whichever
+// happens first. With WithOverrunError, an error is collected if the goroutine
+// is still running at deadline.
+func (m *GoroutineManager) StartForegroundGoroutineDeadline(deadline time.Time, fn func(context.Context), opts ...StartOption) {
+	m.StartForegroundGoroutine(m.withDeadline(deadline, fn, newStartOptions(opts)), opts...)
+}
+
+// Starts a goroutine configured by opts: it can be waited for to finish unless
+// WithBackground is set, its context is cancelled after WithTimeout or at
+// WithDeadline, and the panic policies, name and tags are set by the
+// respective options. This is the general form of the other Start* functions.
+func (m *GoroutineManager) Go(fn func(context.Context), opts ...StartOption) {
+	options := newStartOptions(opts)
+
+	deadline := options.deadline
+	if options.timeout > 0 {
+		if timeoutDeadline := m.options.clock.Now().Add(options.timeout); deadline.IsZero() || timeoutDeadline.Before(deadline) {
+			deadline = timeoutDeadline
+		}
+	}
+
+	if !deadline.IsZero() {
+		fn = m.withDeadline(deadline, fn, options)
+	}
+
+	if options.background {
+		m.StartBackgroundGoroutine(fn, opts...)
+
+		return
+	}
+
+	m.StartForegroundGoroutine(fn, opts...)
+}
+
+// withDeadline wraps fn so that its context is cancelled at deadline. With
+// WithOverrunError, an error is collected if fn is still running at deadline.
+func (m *GoroutineManager) withDeadline(deadline time.Time, fn func(context.Context), options startOptions) func(context.Context) {
+	return func(ctx context.Context) {
+		deadlineCtx, cancel := withDeadline(m.options.clock, ctx, deadline)
+		defer cancel()
+
+		fn(deadlineCtx)
+
+		if options.overrunError && errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+			err := fmt.Errorf("%w: %w", ErrGoroutineOverran, context.DeadlineExceeded)
+			if options.name != "" {
+				err = fmt.Errorf("%v: %w", options.name, err)
+			}
+
+			m.collectError(err)
+		}
+	}
 }
 
 // Stops both foreground and background goroutines by cancelling the goroutine
@@ -98,14 +388,49 @@ func (m *GoroutineManager) StartBackgroundGoroutine(fn func(context.Context)) {
 //
 // Since the goroutine context is cancelled, goroutines started after
 // StopAllGoroutines() is called may return immediately.
+//
+// Child managers created with NewChild are stopped first, most recently
+// created first and depth-first, before the goroutine context is cancelled.
 func (m *GoroutineManager) StopAllGoroutines() {
+	for _, child := range m.children.reversed() {
+		child.StopAllGoroutines()
+	}
+
 	m.cancelInternalCtx(m.errFinished)
 }
 
-// Waits for all foreground goroutines to finish. All calls must return before
-// starting new foreground goroutines.
+// Like StopAllGoroutines(), but first publishes the absolute deadline d from
+// now, by which the goroutines are expected to have returned before they are
+// forcefully terminated, e.g. by the process exiting. Goroutines can retrieve
+// it with ShutdownDeadline() to decide how much work they can still flush.
+// The deadline is published to child managers too. If it was published
+// before, the earlier deadline is kept.
+//
+// The goroutine manager doesn't terminate goroutines at the deadline itself.
+func (m *GoroutineManager) StopAllGoroutinesWithin(d time.Duration) {
+	m.publishShutdownDeadline(m.options.clock.Now().Add(d))
+
+	m.StopAllGoroutines()
+}
+
+// Waits for all foreground goroutines to finish, and for the OnShutdown hook and
+// cleanup functions if the goroutine context is cancelled. All calls must return before
+// starting new foreground goroutines. Child managers are not waited for, see WaitTree().
 func (m *GoroutineManager) Wait() {
+	if d, w := m.options.waitDumpAfter, m.options.waitDumpWriter; d > 0 && w != nil {
+		timer := m.options.clock.AfterFunc(d, func() {
+			m.dumpRemaining(w, d)
+		})
+		defer timer.Stop()
+	}
+
 	m.wg.Wait()
+
+	// Once the goroutine context is cancelled, the shutdown hook and cleanup
+	// functions have to finish too so that their errors are collected
+	if m.internalCtx.Err() != nil {
+		<-m.shutdownDone
+	}
 }
 
 // Gets the goroutine context that should be passed to any child goroutines
@@ -113,39 +438,404 @@ func (m *GoroutineManager) Context() context.Context {
 	return m.internalCtx
 }
 
-// Gets the context cause that is set when a goroutine is stopped by m.StopAllGoroutines()
+// Gets the context cause that is set when a goroutine is stopped by m.StopAllGoroutines().
+// It is distinct for each manager, but always wraps ErrGoroutineStopped.
 func (m *GoroutineManager) GetErrGoroutineStopped() error {
 	return m.errFinished
 }
 
+// startGoroutine creates the state for a goroutine or panic collector and
+// starts tracking it
+func (m *GoroutineManager) startGoroutine(foreground bool, opts []StartOption) *goroutine {
+	g := &goroutine{
+		options:    newStartOptions(opts),
+		foreground: foreground,
+		started:    m.options.clock.Now(),
+		ctx:        m.internalCtx,
+		manager:    m.name,
+	}
+
+	if g.options.detachedDeadline {
+		g.ctx = m.detachedCtx
+	}
+
+	if g.options.hasStopPriority {
+		g.stopLevel = m.stopLevels.add(m.internalCtx, g.options.stopPriority)
+		g.ctx = g.stopLevel.ctx
+	}
+
+	g.managedID = m.nextID.Add(1)
+	m.startReadiness(g)
+
+	m.trace(func() TraceEvent {
+		return g.traceEvent(TraceEventStart, g.started)
+	})
+
+	crossing := m.stats.start(g)
+	m.tracker.add(g)
+	m.updateState(func(l *lifecycle) {
+		l.running++
+	})
+	m.crossHighWatermark(crossing)
+
+	return g
+}
+
+// goroutineContext returns the context to pass to a goroutine's function
+func (m *GoroutineManager) goroutineContext(g *goroutine) context.Context {
+	g.storage = &storage{}
+
+	ctx := context.WithValue(g.ctx, storageKey{}, g.storage)
+	ctx = context.WithValue(ctx, goroutineIDKey{}, g.managedID)
+	ctx = context.WithValue(ctx, readyKey{}, g.readiness)
+	if m.options.goroutineTelemetry {
+		ctx = context.WithValue(ctx, loggerKey{}, Logger(fieldsLogger{m.options.logger, []any{"goroutine", g.options.name, "id", g.managedID}}))
+		ctx = context.WithValue(ctx, statsRecorderKey{}, StatsRecorder(goroutineStatsRecorder{m.stats, map[string]string{LabelGoroutine: g.metricsName()}}))
+	}
+	if g.heartbeat != nil {
+		ctx = context.WithValue(ctx, heartbeatKey{}, g.heartbeat)
+	}
+
+	return ctx
+}
+
+// attachGoroutine associates the state of a goroutine or panic collector with
+// the calling goroutine. It must be called from the goroutine itself.
+func (m *GoroutineManager) attachGoroutine(g *goroutine) {
+	if m.recordsRuntimeIDs() {
+		g.id.Store(currentGoroutineID())
+	}
+
+	if w := m.slowWatchdog.Load(); w.threshold > 0 && w.hook != nil {
+		threshold, hook := w.threshold, w.hook
+		id := currentGoroutineID()
+
+		g.slowTimer = m.options.clock.AfterFunc(threshold, func() {
+			m.options.logger.Warn("goroutine is running slowly", "goroutine", g.options.name, "threshold", threshold)
+
+			hook(g.info(m.options.clock.Now()), goroutineStacks(id)[id])
+		})
+	}
+}
+
+// spawn runs fn with a panic collector on a new goroutine, or inline in
+// synchronous mode. In dry-run mode, it only records the goroutine.
+func (m *GoroutineManager) spawn(g *goroutine, fn func(context.Context)) {
+	if m.dryRun != nil {
+		m.dryRun.record(g)
+
+		m.recoverFromPanics(g)()
+
+		return
+	}
+
+	run := func() {
+		defer m.recoverFromPanics(g)()
+
+		m.runGoroutine(g, fn)
+	}
+
+	if m.options.synchronous {
+		run()
+
+		return
+	}
+
+	go run()
+}
+
+// runGoroutine attaches a goroutine to the calling goroutine and runs fn once
+// admission control and the limits of the goroutine's tags allow it
+func (m *GoroutineManager) runGoroutine(g *goroutine, fn func(context.Context)) {
+	m.attachGoroutine(g)
+
+	ctx := m.goroutineContext(g)
+
+	leave, ok := m.admit(ctx, g)
+	if !ok {
+		return
+	}
+	defer leave()
+
+	release, ok := m.tagLimits.acquire(ctx, g.options.tags)
+	if !ok {
+		return
+	}
+	defer release()
+
+	fn(ctx)
+}
+
+// finishGoroutine releases the state of a goroutine or panic collector,
+// records its statistics and stops tracking it
+func (m *GoroutineManager) finishGoroutine(g *goroutine) {
+	if g.slowTimer != nil {
+		g.slowTimer.Stop()
+	}
+
+	if g.heartbeat != nil {
+		m.health.removeHeartbeat(g)
+	}
+
+	if g.stopLevel != nil {
+		m.stopLevels.remove(g.stopLevel)
+	}
+
+	if g.storage != nil {
+		for _, err := range g.storage.close() {
+			m.collectError(err)
+		}
+	}
+
+	now := m.options.clock.Now()
+
+	m.trace(func() TraceEvent {
+		event := g.traceEvent(TraceEventFinish, now)
+		event.Runtime = now.Sub(g.started)
+
+		return event
+	})
+
+	m.finishReadiness(g)
+
+	crossing := m.stats.finish(g, now)
+	m.tracker.remove(g)
+	m.updateState(func(l *lifecycle) {
+		l.running--
+	})
+	m.crossHighWatermark(crossing)
+}
+
+// crossHighWatermark logs a crossing of the high watermark set with
+// WithGoroutineHighWatermark and calls its hook
+func (m *GoroutineManager) crossHighWatermark(crossing *watermarkCrossing) {
+	if crossing == nil {
+		return
+	}
+
+	if crossing.exceeded {
+		m.options.logger.Warn("goroutine high watermark exceeded", "running", crossing.running, "watermark", crossing.watermark)
+	} else {
+		m.options.logger.Info("goroutine high watermark recovered", "running", crossing.running, "watermark", crossing.watermark)
+	}
+
+	if hook := crossing.hook; hook != nil {
+		m.callHook("OnHighWatermark", func() {
+			hook(crossing.running, crossing.exceeded)
+		})
+	}
+}
+
+// fatal calls the OnFatal hook, falling back to FatalHandler if it is not set
+func (m *GoroutineManager) fatal(err error) {
+	m.health.recordFatal()
+
+	m.options.logger.Error("critical goroutine failed", "error", err)
+
+	if hook := m.hooks.Load().OnFatal; hook != nil {
+		m.callHook("OnFatal", func() {
+			hook(err)
+		})
+
+		return
+	}
+
+	FatalHandler(err)
+}
+
 // recoverFromPanics recovers the last panic and adds the error to errors list.
 // It musT be called from a defer statement, otherwise recover() returns nil.
-func (m *GoroutineManager) recoverFromPanics(track bool) func() {
+// What happens after the error is collected depends on the panic policy.
+func (m *GoroutineManager) recoverFromPanics(g *goroutine) func() {
 	return func() {
-		if track {
+		if g.foreground {
 			defer m.wg.Done()
 		}
 
+		defer m.finishGoroutine(g)
+
+		if g.options.noRecover {
+			return
+		}
+
 		if err := recover(); err != nil {
-			m.errsLock.Lock()
-			defer m.errsLock.Unlock()
-
-			var e error
-			if v, ok := err.(error); ok {
-				e = v
-			} else {
-				e = fmt.Errorf("%v", err)
-			}
+			m.handlePanic(g, err)
+		}
+	}
+}
 
-			if !(errors.Is(e, context.Canceled) && errors.Is(context.Cause(m.internalCtx), m.errFinished)) {
-				*m.errs = errors.Join(*m.errs, e)
+// handlePanic handles a panic recovered from a goroutine: the error is
+// collected, logged, recorded and passed to the hooks, and the goroutine's
+// panic policy is applied
+func (m *GoroutineManager) handlePanic(g *goroutine, err any) {
+	now := m.options.clock.Now()
+	e := newPanicError(g.info(now), err, now)
 
-				if hook := m.hooks.OnAfterRecover; hook != nil {
-					hook()
-				}
-			}
+	if !m.collectPanic(g, e) {
+		return
+	}
 
-			m.cancelInternalCtx(m.errFinished)
+	m.options.logger.Error("recovered panic in goroutine", "goroutine", g.options.name, "id", g.managedID, "error", e)
+
+	m.health.recordPanic(e)
+	m.recordPanicRate(now)
+	m.stats.panic(g)
+	m.trace(func() TraceEvent {
+		event := g.traceEvent(TraceEventPanic, now)
+		event.Error = e.Error()
+
+		return event
+	})
+
+	if m.options.panicProfiles {
+		m.handlePanicProfiles(g, e)
+	}
+
+	if m.options.panicArtifactDir != "" {
+		m.writePanicArtifact(g, e)
+	}
+
+	if hook := m.hooks.Load().OnPanic; hook != nil {
+		m.callHook("OnPanic", func() {
+			hook(g.info(now), e)
+		})
+	}
+
+	for _, to := range g.options.forwardTo {
+		to.forwardPanic(g.info(now), e)
+	}
+
+	if m.parent != nil && m.options.propagatePanics {
+		m.parent.propagatePanic(m.name, e)
+	}
+
+	switch g.options.policyFor(e) {
+	case PanicPolicyRecord:
+		return
+
+	case PanicPolicyRepanic:
+		m.cancelInternalCtx(m.errFinished)
+
+		panic(err)
+
+	case PanicPolicyFatal:
+		m.fatal(e)
+
+		m.cancelInternalCtx(m.errFinished)
+
+	default:
+		m.cancelInternalCtx(m.errFinished)
+	}
+}
+
+// untrackedGoroutine returns the state of code started at started that runs
+// on a goroutine that the manager doesn't track, e.g. an HTTP handler, so that
+// its panics can be handled like the ones of managed goroutines without
+// counting it as a goroutine
+func (m *GoroutineManager) untrackedGoroutine(started time.Time, opts []StartOption) *goroutine {
+	return &goroutine{
+		options:   newStartOptions(opts),
+		started:   started,
+		ctx:       m.internalCtx,
+		managedID: m.nextID.Add(1),
+		manager:   m.name,
+	}
+}
+
+// recoverUntracked returns a function that recovers panics like the one
+// returned by recoverFromPanics, but for code started at started that runs on
+// a goroutine that the manager doesn't track, e.g. a queue task. It must be
+// called from a defer statement.
+func (m *GoroutineManager) recoverUntracked(started time.Time, opts []StartOption) func() {
+	if newStartOptions(opts).noRecover {
+		return func() {}
+	}
+
+	return func() {
+		if err := recover(); err != nil {
+			m.handlePanic(m.untrackedGoroutine(started, opts), err)
 		}
 	}
 }
+
+// forwardPanic collects an error recovered from a panic in a goroutine of
+// another goroutine manager and calls the OnPanic hook
+func (m *GoroutineManager) forwardPanic(info GoroutineInfo, e *PanicError) {
+	m.collectError(e)
+
+	if hook := m.hooks.Load().OnPanic; hook != nil {
+		m.callHook("OnPanic", func() {
+			hook(info, e)
+		})
+	}
+}
+
+// collectError adds an error to the errors list
+func (m *GoroutineManager) collectError(err error) {
+	m.errs.Add(err)
+}
+
+// AllErrors returns the errors collected so far in the order they were
+// collected, without the value errs had when the manager was created. Unlike
+// errs, it is safe to call while goroutines are running, and it works with any
+// ErrorSink.
+func (m *GoroutineManager) AllErrors() []error {
+	return m.errs.Errors()
+}
+
+// SetErrorSink replaces the sink that errors are added to, e.g. so that a
+// long-lived manager collects the errors of each run cycle separately instead
+// of growing one error forever. It returns the errors collected into the
+// previous sink, and AllErrors() only returns the errors collected after the
+// replacement. It is safe to call while goroutines are running.
+func (m *GoroutineManager) SetErrorSink(sink ErrorSink) []error {
+	return m.errs.SetSink(sink)
+}
+
+// SetErrors is like SetErrorSink, but joins the errors into errs like
+// NewGoroutineManager. errs must only be accessed after Wait() returns or the
+// sink is replaced again.
+func (m *GoroutineManager) SetErrors(errs *error) []error {
+	return m.SetErrorSink(JoinErrors(errs))
+}
+
+// collectPanic adds an error recovered from a panic in a goroutine to the
+// errors list. It returns false if the error was caused by stopping the
+// goroutine, in which case it is not collected.
+func (m *GoroutineManager) collectPanic(g *goroutine, e error) bool {
+	if errors.Is(e, context.Canceled) && errors.Is(context.Cause(g.ctx), m.errFinished) {
+		return false
+	}
+
+	m.collectError(e)
+
+	// The hooks are called without holding the lock, so that they can't
+	// deadlock the recovery if they panic or collect errors themselves
+	if hook := m.hooks.Load().OnAfterRecover; hook != nil {
+		m.callHook("OnAfterRecover", hook)
+	}
+
+	if hook := m.hooks.Load().OnAfterRecoverError; hook != nil {
+		m.callHook("OnAfterRecoverError", func() {
+			if err := hook(); err != nil {
+				m.collectError(err)
+			}
+		})
+	}
+
+	return true
+}
+
+// callHook calls fn, which runs a hook, and collects a panic raised by it as
+// an error instead of letting it escape the recovery of another panic
+func (m *GoroutineManager) callHook(name string, fn func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			now := m.options.clock.Now()
+
+			m.collectError(newPanicError(GoroutineInfo{Name: "hook " + name, Started: now}, err, now))
+		}
+	}()
+
+	fn()
+}
@@ -3,6 +3,7 @@ package manager
 import (
 	"context"
 	"errors"
+	"runtime"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -373,6 +374,242 @@ func TestHooks_OnAfterRecover(t *testing.T) {
 	require.Equal(t, uint64(300), counter.Load())
 }
 
+func TestHooks_OnAfterRecoverError(t *testing.T) {
+	t.Parallel()
+
+	errHook := errors.New("hook failed")
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnAfterRecoverError: func() error {
+			return errHook
+		},
+	})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+
+	// Verify both the panic and the hook's error are collected.
+	m.Wait()
+	require.ErrorIs(t, errs, testErr)
+	require.ErrorIs(t, errs, errHook)
+}
+
+func TestCriticalGoroutine(t *testing.T) {
+	// Not parallel since FatalHandler is a package-level variable.
+	fatalHandler := FatalHandler
+	defer func() {
+		FatalHandler = fatalHandler
+	}()
+
+	var fatalErr error
+	FatalHandler = func(err error) {
+		fatalErr = err
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartCriticalGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+
+	// Verify the fatal handler was called after the error was collected.
+	requireNotBlocked(t, m)
+	requireDone(t, m)
+	require.ErrorIs(t, errs, testErr)
+	require.ErrorIs(t, fatalErr, testErr)
+}
+
+func TestCriticalGoroutineStopped(t *testing.T) {
+	fatalHandler := FatalHandler
+	defer func() {
+		FatalHandler = fatalHandler
+	}()
+
+	fatalCalled := false
+	FatalHandler = func(err error) {
+		fatalCalled = true
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartCriticalGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+		panic(ctx.Err())
+	})
+
+	// Verify a graceful stop is not considered fatal.
+	m.StopAllGoroutines()
+	m.Wait()
+	require.False(t, fatalCalled)
+	require.NoError(t, errs)
+}
+
+func TestHooks_OnPanic(t *testing.T) {
+	t.Parallel()
+
+	infos := make(chan GoroutineInfo, 1)
+	panics := make(chan *PanicError, 1)
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnPanic: func(info GoroutineInfo, err *PanicError) {
+			infos <- info
+			panics <- err
+		},
+	})
+
+	started := time.Now()
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		time.Sleep(10 * time.Millisecond)
+
+		panic(testErr)
+	}, WithGoroutineName("worker"), WithTags("a", "b"))
+	m.Wait()
+
+	// Verify the hook receives the goroutine's metadata and the recovered error.
+	info := <-infos
+	require.Equal(t, "worker", info.Name)
+	require.Equal(t, []string{"a", "b"}, info.Tags)
+	require.WithinDuration(t, started, info.Started, 10*time.Millisecond)
+	require.GreaterOrEqual(t, info.Runtime, 10*time.Millisecond)
+
+	err := <-panics
+	require.Equal(t, "worker", err.Name)
+	require.ErrorIs(t, err, testErr)
+
+	// Verify the hook doesn't run for goroutines that were stopped.
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+		panic(ctx.Err())
+	})
+	m.StopAllGoroutines()
+	m.Wait()
+	require.Empty(t, panics)
+}
+
+func TestHooks_OnFatal(t *testing.T) {
+	t.Parallel()
+
+	fatalErrs := make(chan error, 1)
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnFatal: func(err error) {
+			fatalErrs <- err
+		},
+	})
+
+	// Verify non-critical goroutines don't call the hook.
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+	m.Wait()
+	require.ErrorIs(t, errs, testErr)
+	require.Empty(t, fatalErrs)
+
+	// Verify critical goroutines call the hook instead of FatalHandler.
+	err := errors.New("critical error")
+	m.StartCriticalGoroutine(func(_ context.Context) {
+		panic(err)
+	})
+	m.Wait()
+	require.ErrorIs(t, <-fatalErrs, err)
+	require.ErrorIs(t, errs, err)
+}
+
+func TestCleanupGoroutine(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+
+	var errs error
+	m := NewGoroutineManager(context.WithValue(context.Background(), key{}, "value"), &errs, GoroutineManagerHooks{})
+
+	started := make(chan any)
+	done := make(chan any)
+	var (
+		ctxErr error
+		value  any
+	)
+	m.StartCleanupGoroutine(func(ctx context.Context) {
+		close(started)
+		<-done
+
+		ctxErr = ctx.Err()
+		value = ctx.Value(key{})
+	})
+	<-started
+
+	// Verify the cleanup goroutine is still waited for after stopping all
+	// goroutines.
+	m.StopAllGoroutines()
+	requireBlocked(t, m)
+
+	// Verify its context was not cancelled, but still carries values.
+	close(done)
+	m.Wait()
+	require.NoError(t, ctxErr)
+	require.Equal(t, "value", value)
+	require.NoError(t, errs)
+}
+
+func TestForegroundGoroutineDeadline(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify the goroutine context is cancelled at the deadline.
+	var ctxErr error
+	deadline := time.Now().Add(20 * time.Millisecond)
+	m.StartForegroundGoroutineDeadline(deadline, func(ctx context.Context) {
+		<-ctx.Done()
+
+		ctxErr = ctx.Err()
+	})
+	m.Wait()
+	require.ErrorIs(t, ctxErr, context.DeadlineExceeded)
+	require.False(t, time.Now().Before(deadline))
+
+	// Verify no error is collected without WithOverrunError and the manager
+	// isn't stopped.
+	requireNotDone(t, m)
+	require.NoError(t, errs)
+
+	// Verify goroutines that finish in time don't collect an error.
+	m.StartForegroundGoroutineDeadline(time.Now().Add(time.Hour), func(ctx context.Context) {}, WithOverrunError())
+	m.Wait()
+	require.NoError(t, errs)
+
+	// Verify goroutines that overrun collect an error.
+	m.StartForegroundGoroutineDeadline(time.Now().Add(10*time.Millisecond), func(ctx context.Context) {
+		<-ctx.Done()
+	}, WithOverrunError(), WithGoroutineName("worker"))
+	m.Wait()
+	require.ErrorIs(t, errs, ErrGoroutineOverran)
+	require.ErrorIs(t, errs, context.DeadlineExceeded)
+	require.Equal(t, "worker: goroutine overran its deadline: context deadline exceeded", errs.Error())
+}
+
+func TestForegroundGoroutineDeadlineStopped(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutineDeadline(time.Now().Add(time.Hour), func(ctx context.Context) {
+		<-ctx.Done()
+	}, WithOverrunError())
+
+	// Verify stopping all goroutines before the deadline doesn't collect an
+	// error.
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
 // requireBlocked fails if the goroutine manager Wait() method is not blocked.
 func requireBlocked(t *testing.T, m *GoroutineManager) {
 	t.Helper()
@@ -414,3 +651,186 @@ func requireNotDone(t *testing.T, m *GoroutineManager) {
 	default:
 	}
 }
+
+func TestErrGoroutineStopped(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m1 := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	m2 := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m1.StopAllGoroutines()
+	m2.StopAllGoroutines()
+
+	// Verify the causes are distinct for each manager, but match the shared sentinel.
+	require.ErrorIs(t, context.Cause(m1.Context()), ErrGoroutineStopped)
+	require.ErrorIs(t, context.Cause(m2.Context()), ErrGoroutineStopped)
+	require.NotErrorIs(t, context.Cause(m1.Context()), m2.GetErrGoroutineStopped())
+	require.NotErrorIs(t, context.Cause(m2.Context()), m1.GetErrGoroutineStopped())
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	calls := 0
+	m.Wrap(func() {
+		calls++
+	})()
+	require.Equal(t, 1, calls)
+
+	// Verify panics in wrapped functions are collected instead of crashing.
+	done := make(chan any)
+	wrapped := m.Wrap(func() {
+		defer close(done)
+
+		panic(testErr)
+	}, WithGoroutineName("callback"))
+	go wrapped()
+
+	<-done
+	<-m.Context().Done()
+	m.Wait()
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestWrapContext(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	type key struct{}
+
+	// Verify the caller's context is passed through and panics are collected.
+	var value any
+	m.WrapContext(func(ctx context.Context) {
+		value = ctx.Value(key{})
+
+		panic(testErr)
+	})(context.WithValue(context.Background(), key{}, "value"))
+
+	m.Wait()
+	require.Equal(t, "value", value)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestGo(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify goroutines are foreground by default.
+	done := make(chan any)
+	m.Go(func(_ context.Context) {
+		<-done
+	})
+
+	select {
+	case <-m.AllDone():
+		t.Fatal("expected foreground goroutine to be running")
+	default:
+	}
+
+	close(done)
+	m.Wait()
+
+	// Verify background goroutines don't block Wait().
+	bgDone := make(chan any)
+	m.Go(func(ctx context.Context) {
+		defer close(bgDone)
+
+		<-ctx.Done()
+	}, WithBackground())
+	requireNotBlocked(t, m)
+
+	// Verify timeouts cancel the goroutine's context and overruns are collected.
+	var cause error
+	m.Go(func(ctx context.Context) {
+		<-ctx.Done()
+
+		cause = ctx.Err()
+	}, WithTimeout(10*time.Millisecond), WithOverrunError(), WithGoroutineName("timeout"))
+	m.Wait()
+	require.ErrorIs(t, cause, context.DeadlineExceeded)
+	require.ErrorIs(t, errs, ErrGoroutineOverran)
+	require.ErrorContains(t, errs, "timeout: ")
+
+	m.StopAllGoroutines()
+	<-bgDone
+	m.Wait()
+}
+
+func TestGoDeadline(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify the earlier of the deadline and timeout is used.
+	deadline := time.Now().Add(10 * time.Millisecond)
+
+	var actual time.Time
+	m.Go(func(ctx context.Context) {
+		actual, _ = ctx.Deadline()
+	}, WithDeadline(deadline), WithTimeout(time.Hour))
+	m.Wait()
+
+	require.True(t, actual.Equal(deadline))
+	require.NoError(t, errs)
+}
+
+func TestPanicNil(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(nil)
+	})
+	m.Wait()
+
+	// Verify panic(nil) is collected as a runtime error and stops all goroutines.
+	var panicNilErr *runtime.PanicNilError
+	require.ErrorAs(t, errs, &panicNilErr)
+	requireDone(t, m)
+}
+
+func TestPanicInHooks(t *testing.T) {
+	t.Parallel()
+
+	errHook := errors.New("hook failed")
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnAfterRecover: func() {
+			panic(errHook)
+		},
+		OnPanic: func(_ GoroutineInfo, _ *PanicError) {
+			panic(errHook)
+		},
+		OnShutdown: func() error {
+			panic(errHook)
+		},
+	})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+
+	// Verify panics in hooks neither deadlock nor lose the original error.
+	m.Wait()
+	require.ErrorIs(t, errs, testErr)
+	require.ErrorIs(t, errs, errHook)
+	requireDone(t, m)
+
+	names := []string{}
+	for _, p := range PanicsFrom(errs) {
+		names = append(names, p.Name)
+	}
+	require.ElementsMatch(t, []string{"", "hook OnAfterRecover", "hook OnPanic", "hook OnShutdown"}, names)
+}
@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	HealthRecentPanics = 10 // Number of recent panics included in health reports
+)
+
+type heartbeatKey struct{}
+
+// HealthReport summarizes the health of a goroutine manager
+type HealthReport struct {
+	Healthy bool  // True if the manager isn't stopping, no heartbeats are stale, no supervised goroutine exhausted its restarts and no critical goroutine failed
+	State   State // Lifecycle phase of the manager
+	Fatal   bool  // True if a critical goroutine failed
+
+	Foreground GoroutineCounts // Counts of foreground goroutines
+	Background GoroutineCounts // Counts of background goroutines
+
+	RecentPanics    []*PanicError       // Most recent panics, oldest first
+	StaleHeartbeats []GoroutineInfo     // Goroutines that haven't called Heartbeat() within their heartbeat timeout
+	Supervised      []SupervisionStatus // Status of the supervised goroutines in the order they were started
+}
+
+// SupervisionStatus describes the state of a supervised goroutine
+type SupervisionStatus struct {
+	Name      string // Name of the goroutine, if set with WithGoroutineName
+	Restarts  int    // Number of restarts so far
+	Exhausted bool   // True once the restarts set with WithMaxRestarts are exhausted and the goroutine won't be restarted again
+	Reloads   int    // Number of restarts requested with RestartSupervised() so far, which are not included in Restarts
+}
+
+// supervision is the mutable state of a supervised goroutine
+type supervision struct {
+	status SupervisionStatus
+	reload context.CancelCauseFunc // Cancels the context of the running attempt, or nil between attempts
+}
+
+// health keeps track of the state needed for health reports
+type health struct {
+	lock sync.Mutex
+
+	recentPanics []*PanicError
+	fatal        bool
+	heartbeats   map[*goroutine]struct{}
+	supervised   []*supervision // In the order the goroutines were started, so reports are stable
+}
+
+func newHealth() *health {
+	return &health{
+		heartbeats: map[*goroutine]struct{}{},
+	}
+}
+
+// recordPanic adds a panic to the recent panics
+func (h *health) recordPanic(e *PanicError) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.recentPanics = append(h.recentPanics, e)
+	if over := len(h.recentPanics) - HealthRecentPanics; over > 0 {
+		h.recentPanics = append([]*PanicError(nil), h.recentPanics[over:]...)
+	}
+}
+
+// recordFatal records that a critical goroutine failed
+func (h *health) recordFatal() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.fatal = true
+}
+
+// addHeartbeat starts tracking the heartbeats of a goroutine
+func (h *health) addHeartbeat(g *goroutine) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.heartbeats[g] = struct{}{}
+}
+
+// removeHeartbeat stops tracking the heartbeats of a goroutine
+func (h *health) removeHeartbeat(g *goroutine) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.heartbeats, g)
+}
+
+// addSupervision starts reporting a supervised goroutine
+func (h *health) addSupervision(s *supervision) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.supervised = append(h.supervised, s)
+}
+
+// removeSupervision stops reporting a supervised goroutine
+func (h *health) removeSupervision(s *supervision) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.supervised = slices.DeleteFunc(h.supervised, func(other *supervision) bool {
+		return other == s
+	})
+}
+
+// updateSupervision applies fn to the status of a supervised goroutine
+func (h *health) updateSupervision(s *supervision, fn func(status *SupervisionStatus)) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	fn(&s.status)
+}
+
+// setReload sets the function that cancels the running attempt of a
+// supervised goroutine, or nil once the attempt finished
+func (h *health) setReload(s *supervision, reload context.CancelCauseFunc) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	s.reload = reload
+}
+
+// reloadSupervised cancels the running attempts of all supervised goroutines
+// with cause and returns how many were cancelled
+func (h *health) reloadSupervised(cause error) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	n := 0
+	for _, s := range h.supervised {
+		if s.reload != nil {
+			s.reload(cause)
+			n++
+		}
+	}
+
+	return n
+}
+
+// heartbeat holds the heartbeat state of a goroutine
+type heartbeat struct {
+	clock   Clock
+	timeout time.Duration
+	last    atomic.Int64 // Unix time in nanoseconds
+}
+
+// startHeartbeat starts tracking the heartbeats of a goroutine started with
+// WithHeartbeatTimeout
+func (m *GoroutineManager) startHeartbeat(g *goroutine) {
+	if g.options.heartbeatTimeout <= 0 {
+		return
+	}
+
+	g.heartbeat = &heartbeat{
+		clock:   m.options.clock,
+		timeout: g.options.heartbeatTimeout,
+	}
+	g.heartbeat.last.Store(g.started.UnixNano())
+
+	m.health.addHeartbeat(g)
+}
+
+// Heartbeat signals that the goroutine whose context ctx is derived from is
+// still making progress. Goroutines started with WithHeartbeatTimeout that
+// don't call it within the timeout are reported as stale by Health(). It does
+// nothing for other contexts.
+func Heartbeat(ctx context.Context) {
+	if hb, ok := ctx.Value(heartbeatKey{}).(*heartbeat); ok {
+		hb.last.Store(hb.clock.Now().UnixNano())
+	}
+}
+
+// Health returns a report summarizing the health of the goroutine manager, e.g.
+// to back a service health endpoint
+func (m *GoroutineManager) Health() HealthReport {
+	stats := m.Stats()
+	now := m.options.clock.Now()
+
+	report := HealthReport{
+		State: m.State(),
+
+		Foreground: stats.Foreground,
+		Background: stats.Background,
+	}
+
+	m.health.lock.Lock()
+	report.RecentPanics = append([]*PanicError(nil), m.health.recentPanics...)
+	report.Fatal = m.health.fatal
+
+	for g := range m.health.heartbeats {
+		if last := time.Unix(0, g.heartbeat.last.Load()); now.Sub(last) > g.heartbeat.timeout {
+			report.StaleHeartbeats = append(report.StaleHeartbeats, g.info(now))
+		}
+	}
+
+	for _, s := range m.health.supervised {
+		report.Supervised = append(report.Supervised, s.status)
+	}
+	m.health.lock.Unlock()
+
+	report.Healthy = (report.State == StateIdle || report.State == StateRunning) && len(report.StaleHeartbeats) == 0 && !report.Fatal
+	for _, status := range report.Supervised {
+		if status.Exhausted {
+			report.Healthy = false
+		}
+	}
+
+	return report
+}
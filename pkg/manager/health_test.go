@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	report := m.Health()
+	require.True(t, report.Healthy)
+	require.Equal(t, StateIdle, report.State)
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	// Verify recent panics are reported.
+	report = m.Health()
+	require.True(t, report.Healthy)
+	require.Equal(t, GoroutineCounts{Started: 1, Finished: 1}, report.Foreground)
+	require.Len(t, report.RecentPanics, 1)
+	require.ErrorIs(t, report.RecentPanics[0], testErr)
+
+	// Verify the manager is unhealthy once stopped.
+	m.StopAllGoroutines()
+	m.Wait()
+	require.False(t, m.Health().Healthy)
+}
+
+func TestHealthHeartbeats(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	beat := make(chan any)
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-beat:
+				Heartbeat(ctx)
+			}
+		}
+	}, WithGoroutineName("worker"), WithHeartbeatTimeout(20*time.Millisecond))
+
+	require.True(t, m.Health().Healthy)
+
+	// Verify goroutines that don't call Heartbeat() in time are reported.
+	require.Eventually(t, func() bool {
+		report := m.Health()
+
+		return !report.Healthy && len(report.StaleHeartbeats) == 1 && report.StaleHeartbeats[0].Name == "worker"
+	}, time.Second, time.Millisecond)
+
+	// Verify the goroutine is healthy again after a heartbeat.
+	beat <- struct{}{}
+	require.Eventually(t, func() bool {
+		return len(m.Health().StaleHeartbeats) == 0
+	}, 10*time.Millisecond, time.Millisecond)
+
+	m.StopAllGoroutines()
+	m.Wait()
+}
+
+func TestHealthSupervised(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartSupervisedGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("worker"), WithBackoff(ConstantBackoff(time.Millisecond)), WithMaxRestarts(2))
+	m.Wait()
+
+	// Verify supervised goroutines that exhausted their restarts are reported.
+	report := m.Health()
+	require.False(t, report.Healthy)
+	require.Equal(t, []SupervisionStatus{{Name: "worker", Restarts: 2, Exhausted: true}}, report.Supervised)
+}
+
+func TestHealthSupervisedOrder(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	names := []string{"c", "a", "d", "b", "e"}
+	for _, name := range names {
+		m.StartSupervisedGoroutine(func(ctx context.Context) {
+			<-ctx.Done()
+		}, WithGoroutineName(name))
+	}
+
+	// Verify the supervised goroutines are reported in the order they were
+	// started, every time.
+	for range 10 {
+		var reported []string
+		for _, status := range m.Health().Supervised {
+			reported = append(reported, status.Name)
+		}
+		require.Equal(t, names, reported)
+	}
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
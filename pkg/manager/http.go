@@ -0,0 +1,114 @@
+package manager
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// LivenessHandler returns a handler for a Kubernetes liveness probe. It
+// responds with 503 Service Unavailable if the goroutine manager is in a
+// condition that a restart is needed to recover from, i.e. a critical
+// goroutine failed, a heartbeat is stale or a supervised goroutine exhausted
+// its restarts, and with 200 OK otherwise.
+func (m *GoroutineManager) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		report := m.Health()
+
+		reason := ""
+		switch {
+		case report.Fatal:
+			reason = "critical goroutine failed"
+
+		case len(report.StaleHeartbeats) > 0:
+			reason = fmt.Sprintf("%v stale heartbeats", len(report.StaleHeartbeats))
+
+		default:
+			for _, status := range report.Supervised {
+				if status.Exhausted {
+					reason = "supervised goroutine exhausted its restarts"
+				}
+			}
+		}
+
+		writeProbe(w, reason)
+	})
+}
+
+// ReadinessHandler returns a handler for a Kubernetes readiness probe. It
+// responds with 503 Service Unavailable if the goroutine manager is quiesced,
+// stopping, stopped or unhealthy, so that traffic is drained from it, and with
+// 200 OK otherwise.
+func (m *GoroutineManager) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		report := m.Health()
+
+		reason := ""
+		switch {
+		case report.State == StateStopping || report.State == StateStopped:
+			reason = report.State.String()
+
+		case m.isQuiesced():
+			reason = "quiesced"
+
+		case !report.Healthy:
+			reason = "unhealthy"
+		}
+
+		writeProbe(w, reason)
+	})
+}
+
+// httpGroup is the value of LabelGoroutine for panics of HTTP handlers, so
+// that request paths don't add a series each
+const httpGroup = "http"
+
+// HTTPRecoverer returns a middleware that recovers panics of next like the
+// goroutine manager recovers the panics of its goroutines with
+// PanicPolicyRecord: the error goes through the same panic handling, i.e. it
+// is collected, logged, recorded in metrics, traces and artifacts and passed
+// to the hooks, and the client receives a 500 Internal Server Error. The
+// goroutine name in the error is the request's method and path, and its
+// metrics are labeled with "http". Other goroutines are not stopped, and
+// requests are not counted as goroutines. http.ErrAbortHandler is re-raised,
+// so that net/http can abort the response.
+func (m *GoroutineManager) HTTPRecoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := m.options.clock.Now()
+
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			m.handlePanic(m.untrackedGoroutine(started, []StartOption{
+				WithGoroutineName(r.Method + " " + r.URL.Path),
+				withGroup(httpGroup),
+				WithPanicPolicy(PanicPolicyRecord),
+			}), err)
+
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeProbe writes the response of a probe, which failed if reason is set
+func writeProbe(w http.ResponseWriter, reason string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if reason != "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, reason)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
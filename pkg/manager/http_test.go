@@ -0,0 +1,155 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// probe returns the status code and body of a probe handler
+func probe(t *testing.T, handler http.Handler) (int, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	return rec.Code, rec.Body.String()
+}
+
+func TestReadinessHandler(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	code, body := probe(t, m.ReadinessHandler())
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok\n", body)
+
+	// Verify the manager is not ready once quiesced.
+	m.Quiesce()
+	code, body = probe(t, m.ReadinessHandler())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "quiesced\n", body)
+
+	// Verify the manager is not ready once stopped.
+	m.StopAllGoroutines()
+	m.Wait()
+	code, body = probe(t, m.ReadinessHandler())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "stopped\n", body)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnFatal: func(_ error) {},
+	})
+
+	code, _ := probe(t, m.LivenessHandler())
+	require.Equal(t, http.StatusOK, code)
+
+	// Verify a stopped manager is still live.
+	m.StopAllGoroutines()
+	m.Wait()
+	code, _ = probe(t, m.LivenessHandler())
+	require.Equal(t, http.StatusOK, code)
+
+	// Verify the manager is not live once a critical goroutine failed.
+	m.StartCriticalGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+	m.Wait()
+
+	code, body := probe(t, m.LivenessHandler())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "critical goroutine failed\n", body)
+}
+
+func TestHTTPRecoverer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		errs      error
+		hooked    GoroutineInfo
+		recovered bool
+	)
+	r := newTestRecorder()
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnPanic: func(info GoroutineInfo, _ *PanicError) {
+			hooked = info
+		},
+		OnAfterRecover: func() {
+			recovered = true
+		},
+	}, WithStatsRecorder(r))
+
+	handler := m.HTTPRecoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic(testErr)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// Verify the panic is answered with a 500 and goes through the manager's
+	// panic handling.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/panic", nil))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	require.Equal(t, "POST /panic", hooked.Name)
+	require.True(t, recovered)
+	require.Len(t, m.Health().RecentPanics, 1)
+	require.NoError(t, m.Context().Err())
+
+	// Verify the panic is counted under one label for all requests, and the
+	// request isn't counted as a goroutine.
+	r.lock.Lock()
+	require.Equal(t, float64(1), r.counters[MetricPanics+"{http}"])
+	r.lock.Unlock()
+	require.Zero(t, m.Stats().Background.Started)
+
+	m.Wait()
+
+	var p *PanicError
+	require.ErrorAs(t, errs, &p)
+	require.ErrorIs(t, p, testErr)
+	require.Equal(t, "POST /panic", p.Name)
+
+	// Verify aborted handlers are left to net/http.
+	aborting := m.HTTPRecoverer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		aborting.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestHTTPRecovererManagerName(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithName("api"))
+
+	handler := m.HTTPRecoverer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(testErr)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	m.Wait()
+
+	// Verify panics of handlers carry the manager's name like the ones of goroutines.
+	var p *PanicError
+	require.ErrorAs(t, errs, &p)
+	require.Equal(t, "api", p.Manager)
+}
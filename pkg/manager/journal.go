@@ -0,0 +1,362 @@
+package manager
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrQueueNotJournaled = errors.New("queue has no journal")    // Returned if a payload is enqueued in a queue that was created without WithJournal
+	ErrJournalCorrupt    = errors.New("journal file is corrupt") // Returned by NewFileJournal if a record other than the last one can't be read
+)
+
+// JournalEntry is a pending task recorded in a journal
+type JournalEntry struct {
+	ID      uint64 // ID assigned by the journal
+	Payload []byte // Payload that is passed to the queue's handler
+}
+
+// Journal stores the pending tasks of a queue, so that the tasks that didn't
+// complete before a process restart can be enqueued again. Implementations
+// must be safe for concurrent use.
+type Journal interface {
+	Append(payload []byte) (uint64, error) // Records a pending task and returns its ID
+	Complete(id uint64) error              // Marks a task as completed
+	Pending() ([]JournalEntry, error)      // Returns the pending tasks in the order they were appended
+}
+
+// WithJournal records the tasks enqueued with EnqueuePayload and
+// TryEnqueuePayload in journal until handler returns for them without
+// panicking. When the queue is created, the pending tasks of journal are
+// enqueued again, so that work that didn't complete before a process restart
+// is picked up.
+func WithJournal(journal Journal, handler func(ctx context.Context, payload []byte)) QueueOption {
+	return func(o *queueOptions) {
+		o.journal = journal
+		o.journalHandler = handler
+	}
+}
+
+// EnqueuePayload records a task with payload in the queue's journal and adds
+// it to the queue, blocking while the queue is full. It returns
+// ErrQueueNotJournaled if the queue was created without WithJournal, and the
+// error of the journal if the task couldn't be recorded. See Enqueue().
+func (q *Queue) EnqueuePayload(ctx context.Context, payload []byte, opts ...TaskOption) error {
+	if q.options.journal == nil {
+		return ErrQueueNotJournaled
+	}
+
+	if err := q.acquire(ctx); err != nil {
+		return err
+	}
+
+	return q.pushPayload(payload, opts)
+}
+
+// TryEnqueuePayload records a task with payload in the queue's journal and
+// adds it to the queue without blocking. See EnqueuePayload() and
+// TryEnqueue().
+func (q *Queue) TryEnqueuePayload(payload []byte, opts ...TaskOption) error {
+	if q.options.journal == nil {
+		return ErrQueueNotJournaled
+	}
+
+	if err := q.tryAcquire(); err != nil {
+		return err
+	}
+
+	return q.pushPayload(payload, opts)
+}
+
+// pushPayload records a task with payload and adds it to the queue. The
+// caller must have acquired a slot.
+func (q *Queue) pushPayload(payload []byte, opts []TaskOption) error {
+	id, err := q.options.journal.Append(payload)
+	if err != nil {
+		<-q.slots
+
+		return err
+	}
+
+	q.push(q.journaled(JournalEntry{ID: id, Payload: payload}), opts)
+
+	return nil
+}
+
+// journaled returns a task that calls the queue's handler with the payload of
+// entry and marks it as completed in the journal
+func (q *Queue) journaled(entry JournalEntry) func(context.Context) {
+	return func(ctx context.Context) {
+		q.options.journalHandler(ctx, entry.Payload)
+
+		if err := q.options.journal.Complete(entry.ID); err != nil {
+			q.m.collectError(fmt.Errorf("could not complete task %v in journal of queue %q: %w", entry.ID, q.name, err))
+		}
+	}
+}
+
+// replay enqueues the pending tasks of the queue's journal again
+func (q *Queue) replay() {
+	entries, err := q.options.journal.Pending()
+	if err != nil {
+		q.m.collectError(fmt.Errorf("could not read journal of queue %q: %w", q.name, err))
+
+		return
+	}
+
+	if len(entries) == 0 {
+		return
+	}
+
+	q.m.StartForegroundGoroutine(func(ctx context.Context) {
+		for _, entry := range entries {
+			if err := q.acquire(ctx); err != nil {
+				return
+			}
+
+			q.push(q.journaled(entry), nil)
+		}
+	}, WithGoroutineName(q.name+" journal"))
+}
+
+// MemoryJournal is a journal that keeps the pending tasks in memory, e.g. for
+// tests or to survive the restart of a queue, but not of the process
+type MemoryJournal struct {
+	lock sync.Mutex
+
+	next    uint64
+	pending map[uint64][]byte
+}
+
+// NewMemoryJournal creates an empty in-memory journal
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{
+		pending: map[uint64][]byte{},
+	}
+}
+
+func (j *MemoryJournal) Append(payload []byte) (uint64, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.next++
+	j.pending[j.next] = payload
+
+	return j.next, nil
+}
+
+func (j *MemoryJournal) Complete(id uint64) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	delete(j.pending, id)
+
+	return nil
+}
+
+func (j *MemoryJournal) Pending() ([]JournalEntry, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	entries := make([]JournalEntry, 0, len(j.pending))
+	for id, payload := range j.pending {
+		entries = append(entries, JournalEntry{ID: id, Payload: payload})
+	}
+	slices.SortFunc(entries, func(a, b JournalEntry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return entries, nil
+}
+
+// journalRecord is a line of the file of a FileJournal
+type journalRecord struct {
+	Op      string `json:"op"` // Either "append" or "complete"
+	ID      uint64 `json:"id"`
+	Payload []byte `json:"payload,omitempty"`
+}
+
+// FileJournal is a journal that appends the pending and completed tasks to a
+// file, one JSON record per line, and syncs it after each record
+type FileJournal struct {
+	lock sync.Mutex
+
+	file   *os.File
+	memory *MemoryJournal
+}
+
+// NewFileJournal opens the journal stored at path, creating it if it doesn't
+// exist. The file is compacted so that it only contains the pending tasks. A
+// truncated last record, e.g. from a crash while writing it, is ignored. If any
+// other record can't be read, an error wrapping ErrJournalCorrupt is returned
+// and the file is left as is, so that the records after it aren't lost.
+func NewFileJournal(path string) (*FileJournal, error) {
+	memory := NewMemoryJournal()
+
+	if file, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(nil, 64*1024*1024)
+
+		var (
+			line    int
+			corrupt error // Error of the previous record, which is only tolerated if it is the last one
+		)
+		for scanner.Scan() {
+			line++
+
+			if corrupt != nil {
+				_ = file.Close()
+
+				return nil, corrupt
+			}
+
+			var record journalRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				corrupt = fmt.Errorf("%w: %v: line %v: %w", ErrJournalCorrupt, path, line, err)
+
+				continue
+			}
+
+			switch record.Op {
+			case "append":
+				memory.pending[record.ID] = record.Payload
+
+			case "complete":
+				delete(memory.pending, record.ID)
+			}
+
+			memory.next = max(memory.next, record.ID)
+		}
+
+		err := scanner.Err()
+		_ = file.Close()
+
+		if err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	j := &FileJournal{
+		memory: memory,
+	}
+
+	if err := j.compact(path); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// compact writes the pending tasks to a new file that replaces path and opens
+// it for appending
+func (j *FileJournal) compact(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	entries, _ := j.memory.Pending()
+	for _, entry := range entries {
+		if err := writeJournalRecord(tmp, journalRecord{Op: "append", ID: entry.ID, Payload: entry.Payload}); err != nil {
+			_ = tmp.Close()
+
+			return err
+		}
+	}
+
+	// Keep the next ID, so that IDs aren't reused even if no task is pending
+	if len(entries) == 0 && j.memory.next > 0 {
+		if err := writeJournalRecord(tmp, journalRecord{Op: "complete", ID: j.memory.next}); err != nil {
+			_ = tmp.Close()
+
+			return err
+		}
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	j.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+
+	return err
+}
+
+func writeJournalRecord(file *os.File, record journalRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(line, '\n'))
+
+	return err
+}
+
+// write appends a record to the file and syncs it
+func (j *FileJournal) write(record journalRecord) error {
+	if err := writeJournalRecord(j.file, record); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+func (j *FileJournal) Append(payload []byte) (uint64, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	id, _ := j.memory.Append(payload)
+
+	if err := j.write(journalRecord{Op: "append", ID: id, Payload: payload}); err != nil {
+		_ = j.memory.Complete(id)
+
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (j *FileJournal) Complete(id uint64) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if err := j.write(journalRecord{Op: "complete", ID: id}); err != nil {
+		return err
+	}
+
+	return j.memory.Complete(id)
+}
+
+func (j *FileJournal) Pending() ([]JournalEntry, error) {
+	return j.memory.Pending()
+}
+
+// Close closes the file of the journal
+func (j *FileJournal) Close() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.file.Close()
+}
@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueJournal(t *testing.T) {
+	t.Parallel()
+
+	journal := NewMemoryJournal()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.ErrorIs(t, m.NewQueue("plain", 1, 1).TryEnqueuePayload(nil), ErrQueueNotJournaled)
+
+	// Without workers, the tasks stay pending.
+	q := m.NewQueue("test", 0, 10, WithJournal(journal, func(context.Context, []byte) {}))
+
+	require.NoError(t, q.EnqueuePayload(context.Background(), []byte("a")))
+	require.NoError(t, q.TryEnqueuePayload([]byte("b")))
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	// Verify the tasks that didn't run are still pending.
+	entries, err := journal.Pending()
+	require.NoError(t, err)
+	require.Equal(t, []JournalEntry{{ID: 1, Payload: []byte("a")}, {ID: 2, Payload: []byte("b")}}, entries)
+
+	// Verify a new queue runs the pending tasks and completes them.
+	m = NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		lock     sync.Mutex
+		payloads []string
+		wg       sync.WaitGroup
+	)
+	wg.Add(2)
+	m.NewQueue("test", 1, 1, WithJournal(journal, func(_ context.Context, payload []byte) {
+		defer wg.Done()
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		payloads = append(payloads, string(payload))
+	}))
+	wg.Wait()
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.Equal(t, []string{"a", "b"}, payloads)
+
+	entries, err = journal.Pending()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	require.NoError(t, errs)
+}
+
+func TestFileJournal(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "journal")
+
+	j, err := NewFileJournal(path)
+	require.NoError(t, err)
+
+	for _, payload := range []string{"a", "b", "c"} {
+		_, err := j.Append([]byte(payload))
+		require.NoError(t, err)
+	}
+	require.NoError(t, j.Complete(2))
+	require.NoError(t, j.Close())
+
+	// Simulate a crash while writing a record.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"app`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Verify the pending tasks survive reopening the journal.
+	j, err = NewFileJournal(path)
+	require.NoError(t, err)
+
+	entries, err := j.Pending()
+	require.NoError(t, err)
+	require.Equal(t, []JournalEntry{{ID: 1, Payload: []byte("a")}, {ID: 3, Payload: []byte("c")}}, entries)
+
+	// Verify IDs aren't reused after compaction.
+	require.NoError(t, j.Complete(1))
+	require.NoError(t, j.Complete(3))
+	require.NoError(t, j.Close())
+
+	j, err = NewFileJournal(path)
+	require.NoError(t, err)
+	defer j.Close()
+
+	id, err := j.Append([]byte("d"))
+	require.NoError(t, err)
+	require.EqualValues(t, 4, id)
+}
+
+func TestFileJournalCorrupt(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "journal")
+
+	j, err := NewFileJournal(path)
+	require.NoError(t, err)
+
+	_, err = j.Append([]byte("a"))
+	require.NoError(t, err)
+	require.NoError(t, j.Close())
+
+	// Simulate a corrupt record followed by a valid one.
+	record, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("{\"op\":\"app\n" + string(record))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// Verify corruption before the last record fails without rewriting the file.
+	_, err = NewFileJournal(path)
+	require.ErrorIs(t, err, ErrJournalCorrupt)
+	require.ErrorContains(t, err, "line 2")
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+}
@@ -0,0 +1,208 @@
+package manager
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTaskPanicked = errors.New("task panicked") // Returned to callers waiting for a task that panicked; the panic itself is collected by the goroutine manager
+)
+
+// KeyedTasksOption configures keyed tasks
+type KeyedTasksOption func(*keyedTasksOptions)
+
+type keyedTasksOptions struct {
+	cacheTTL        time.Duration
+	cacheMaxEntries int
+	startOptions    []StartOption
+}
+
+// WithCacheTTL caches successful results for ttl, so that calls for the same
+// key are served from the cache instead of running the task again
+func WithCacheTTL(ttl time.Duration) KeyedTasksOption {
+	return func(o *keyedTasksOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// WithCacheMaxEntries limits the number of cached results; once the limit is
+// reached, the oldest results are evicted first
+func WithCacheMaxEntries(maxEntries int) KeyedTasksOption {
+	return func(o *keyedTasksOptions) {
+		o.cacheMaxEntries = maxEntries
+	}
+}
+
+// WithTaskStartOptions sets the options for the goroutines running the tasks
+func WithTaskStartOptions(opts ...StartOption) KeyedTasksOption {
+	return func(o *keyedTasksOptions) {
+		o.startOptions = append(o.startOptions, opts...)
+	}
+}
+
+type keyedCall[V any] struct {
+	done chan struct{}
+
+	value V
+	err   error
+}
+
+type keyedResult[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// KeyedTasks runs tasks identified by a key on background goroutines of a
+// goroutine manager. Concurrent calls for the same key share one execution of
+// the task, and results can optionally be cached.
+type KeyedTasks[K comparable, V any] struct {
+	m       *GoroutineManager
+	options keyedTasksOptions
+
+	lock    sync.Mutex
+	calls   map[K]*keyedCall[V]
+	cache   map[K]*list.Element
+	results *list.List
+}
+
+// NewKeyedTasks creates keyed tasks that run on background goroutines of m
+func NewKeyedTasks[K comparable, V any](m *GoroutineManager, opts ...KeyedTasksOption) *KeyedTasks[K, V] {
+	var options keyedTasksOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &KeyedTasks[K, V]{
+		m:       m,
+		options: options,
+
+		calls:   map[K]*keyedCall[V]{},
+		cache:   map[K]*list.Element{},
+		results: list.New(),
+	}
+}
+
+// Do returns the result of fn for key. If a cached result exists, it is
+// returned directly; otherwise fn is started on a background goroutine with
+// the goroutine context, unless it is already running for key. Do returns
+// early with ctx.Err() if ctx is cancelled before fn returns, in which case fn
+// keeps running for other callers.
+func (k *KeyedTasks[K, V]) Do(ctx context.Context, key K, fn func(context.Context) (V, error)) (V, error) {
+	k.lock.Lock()
+
+	if value, ok := k.cached(key); ok {
+		k.lock.Unlock()
+
+		return value, nil
+	}
+
+	call, running := k.calls[key]
+	if !running {
+		call = &keyedCall[V]{
+			done: make(chan struct{}),
+		}
+		k.calls[key] = call
+	}
+
+	k.lock.Unlock()
+
+	// The task runs inline in synchronous mode, so the lock must not be held
+	if !running {
+		k.m.StartBackgroundGoroutine(func(goroutineCtx context.Context) {
+			returned := false
+			defer func() {
+				if !returned {
+					call.err = ErrTaskPanicked
+				}
+
+				k.lock.Lock()
+				defer k.lock.Unlock()
+
+				delete(k.calls, key)
+
+				if call.err == nil {
+					k.store(key, call.value)
+				}
+
+				close(call.done)
+			}()
+
+			call.value, call.err = fn(goroutineCtx)
+			returned = true
+		}, k.options.startOptions...)
+	}
+
+	select {
+	case <-ctx.Done():
+		var value V
+
+		return value, ctx.Err()
+
+	case <-call.done:
+		return call.value, call.err
+	}
+}
+
+// Forget removes the cached result for key
+func (k *KeyedTasks[K, V]) Forget(key K) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if element, ok := k.cache[key]; ok {
+		k.results.Remove(element)
+		delete(k.cache, key)
+	}
+}
+
+// cached returns the cached result for key if it hasn't expired. It must be
+// called with the lock held.
+func (k *KeyedTasks[K, V]) cached(key K) (V, bool) {
+	element, ok := k.cache[key]
+	if !ok {
+		var value V
+
+		return value, false
+	}
+
+	result := element.Value.(*keyedResult[K, V])
+	if k.m.options.clock.Now().After(result.expires) {
+		k.results.Remove(element)
+		delete(k.cache, key)
+
+		var value V
+
+		return value, false
+	}
+
+	return result.value, true
+}
+
+// store caches a result, evicting the oldest results if the cache is full. It
+// must be called with the lock held.
+func (k *KeyedTasks[K, V]) store(key K, value V) {
+	if k.options.cacheTTL <= 0 {
+		return
+	}
+
+	if element, ok := k.cache[key]; ok {
+		k.results.Remove(element)
+		delete(k.cache, key)
+	}
+
+	k.cache[key] = k.results.PushBack(&keyedResult[K, V]{
+		key:     key,
+		value:   value,
+		expires: k.m.options.clock.Now().Add(k.options.cacheTTL),
+	})
+
+	for k.options.cacheMaxEntries > 0 && k.results.Len() > k.options.cacheMaxEntries {
+		oldest := k.results.Remove(k.results.Front()).(*keyedResult[K, V])
+
+		delete(k.cache, oldest.key)
+	}
+}
@@ -0,0 +1,159 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyedTasksDeduplicate(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	tasks := NewKeyedTasks[string, int](m)
+
+	var (
+		calls atomic.Int64
+		wg    sync.WaitGroup
+	)
+	done := make(chan any)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			value, err := tasks.Do(context.Background(), "key", func(_ context.Context) (int, error) {
+				calls.Add(1)
+				<-done
+
+				return 42, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		}()
+	}
+
+	// Verify concurrent calls share one execution.
+	require.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	close(done)
+	wg.Wait()
+	require.Equal(t, int64(1), calls.Load())
+
+	// Verify results are not cached by default.
+	value, err := tasks.Do(context.Background(), "key", func(_ context.Context) (int, error) {
+		return 43, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 43, value)
+	require.NoError(t, errs)
+}
+
+func TestKeyedTasksCache(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	tasks := NewKeyedTasks[string, int](m, WithCacheTTL(50*time.Millisecond), WithCacheMaxEntries(2))
+
+	var calls atomic.Int64
+	do := func(key string) int {
+		value, err := tasks.Do(context.Background(), key, func(_ context.Context) (int, error) {
+			return int(calls.Add(1)), nil
+		})
+		require.NoError(t, err)
+
+		return value
+	}
+
+	// Verify results are served from the cache.
+	require.Equal(t, 1, do("a"))
+	require.Equal(t, 1, do("a"))
+
+	// Verify the oldest result is evicted once the cache is full.
+	require.Equal(t, 2, do("b"))
+	require.Equal(t, 3, do("c"))
+	require.Equal(t, 4, do("a"))
+	require.Equal(t, 3, do("c"))
+
+	// Verify results can be forgotten.
+	tasks.Forget("c")
+	require.Equal(t, 5, do("c"))
+
+	// Verify results expire.
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, 6, do("c"))
+	require.NoError(t, errs)
+}
+
+func TestKeyedTasksErrors(t *testing.T) {
+	t.Parallel()
+
+	panics := make(chan *PanicError, 1)
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnPanic: func(_ GoroutineInfo, err *PanicError) {
+			panics <- err
+		},
+	})
+	tasks := NewKeyedTasks[string, int](m, WithCacheTTL(time.Hour), WithTaskStartOptions(WithPanicPolicy(PanicPolicyRecord)))
+
+	// Verify errors are returned, but not cached.
+	_, err := tasks.Do(context.Background(), "key", func(_ context.Context) (int, error) {
+		return 0, testErr
+	})
+	require.ErrorIs(t, err, testErr)
+
+	// Verify panics are collected and callers are notified.
+	_, err = tasks.Do(context.Background(), "key", func(_ context.Context) (int, error) {
+		panic(testErr)
+	})
+	require.ErrorIs(t, err, ErrTaskPanicked)
+	require.ErrorIs(t, <-panics, testErr)
+
+	value, err := tasks.Do(context.Background(), "key", func(_ context.Context) (int, error) {
+		return 42, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+
+	// Verify callers can stop waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = tasks.Do(ctx, "other", func(ctx context.Context) (int, error) {
+		return 0, nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestKeyedTasksSynchronous(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSynchronous())
+	tasks := NewKeyedTasks[string, int](m, WithCacheTTL(time.Hour))
+
+	// Verify tasks running inline don't deadlock and their results are cached.
+	value, err := tasks.Do(context.Background(), "key", func(_ context.Context) (int, error) {
+		return 42, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+
+	value, err = tasks.Do(context.Background(), "key", func(_ context.Context) (int, error) {
+		return 43, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+	require.NoError(t, errs)
+}
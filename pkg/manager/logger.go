@@ -0,0 +1,42 @@
+package manager
+
+// Logger is used by the goroutine manager for lifecycle and error logging.
+// keysAndValues are alternating keys and values, which makes *slog.Logger
+// implement it directly.
+type Logger interface {
+	Debug(msg string, keysAndValues ...any)
+	Info(msg string, keysAndValues ...any)
+	Warn(msg string, keysAndValues ...any)
+	Error(msg string, keysAndValues ...any)
+}
+
+// nopLogger is the default logger, which discards all messages
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// fieldsLogger adds keys and values to each message, e.g. the name of the
+// goroutine manager
+type fieldsLogger struct {
+	logger Logger
+	fields []any
+}
+
+func (l fieldsLogger) Debug(msg string, keysAndValues ...any) {
+	l.logger.Debug(msg, append(l.fields[:len(l.fields):len(l.fields)], keysAndValues...)...)
+}
+
+func (l fieldsLogger) Info(msg string, keysAndValues ...any) {
+	l.logger.Info(msg, append(l.fields[:len(l.fields):len(l.fields)], keysAndValues...)...)
+}
+
+func (l fieldsLogger) Warn(msg string, keysAndValues ...any) {
+	l.logger.Warn(msg, append(l.fields[:len(l.fields):len(l.fields)], keysAndValues...)...)
+}
+
+func (l fieldsLogger) Error(msg string, keysAndValues ...any) {
+	l.logger.Error(msg, append(l.fields[:len(l.fields):len(l.fields)], keysAndValues...)...)
+}
@@ -0,0 +1,32 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithLogger(logger))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("worker"))
+	m.Wait()
+
+	// Verify the panic and lifecycle are logged.
+	out := buf.String()
+	require.Contains(t, out, `level=ERROR msg="recovered panic in goroutine" goroutine=worker`)
+	require.Contains(t, out, `level=INFO msg="stopping goroutines"`)
+	require.Contains(t, out, `level=DEBUG msg="goroutine manager state changed" old=idle new=running`)
+	require.ErrorIs(t, errs, testErr)
+}
@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"context"
+	"sync"
+)
+
+// onceNames holds the names of the goroutines started with StartOnce
+type onceNames struct {
+	lock    sync.Mutex
+	started map[string]struct{}
+}
+
+func newOnceNames() *onceNames {
+	return &onceNames{
+		started: map[string]struct{}{},
+	}
+}
+
+// claim marks name as started. It returns false if it was started before.
+func (o *onceNames) claim(name string) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if _, ok := o.started[name]; ok {
+		return false
+	}
+	o.started[name] = struct{}{}
+
+	return true
+}
+
+// Starts a foreground goroutine named name unless a goroutine with that name
+// was started with StartOnce before during the manager's lifetime, even if it
+// has finished since, e.g. for lazily started singletons like keepalive loops.
+// It returns whether the goroutine was started.
+func (m *GoroutineManager) StartOnce(name string, fn func(context.Context), opts ...StartOption) bool {
+	if !m.once.claim(name) {
+		return false
+	}
+
+	m.StartForegroundGoroutine(fn, append(opts[:len(opts):len(opts)], WithGoroutineName(name))...)
+
+	return true
+}
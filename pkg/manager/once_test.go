@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartOnce(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		runs    atomic.Int32
+		started atomic.Int32
+		wg      sync.WaitGroup
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if m.StartOnce("keepalive", func(_ context.Context) {
+				runs.Add(1)
+			}) {
+				started.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	m.Wait()
+
+	// Verify the goroutine is started once, even after it finished.
+	require.Equal(t, int32(1), started.Load())
+	require.Equal(t, int32(1), runs.Load())
+	require.False(t, m.StartOnce("keepalive", func(_ context.Context) {}))
+
+	// Verify other names are independent.
+	require.True(t, m.StartOnce("flusher", func(_ context.Context) {}))
+	m.Wait()
+
+	require.NoError(t, errs)
+}
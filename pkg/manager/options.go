@@ -0,0 +1,567 @@
+package manager
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"time"
+)
+
+// PanicPolicy configures how the goroutine manager reacts to a panic
+type PanicPolicy int
+
+const (
+	PanicPolicyRecordAndCancelAll PanicPolicy = iota // Collects the error and stops all goroutines (default)
+	PanicPolicyRecord                                // Collects the error, but keeps other goroutines running
+	PanicPolicyRepanic                               // Collects the error, stops all goroutines and re-raises the panic
+	PanicPolicyFatal                                 // Collects the error, calls the OnFatal hook and stops all goroutines
+)
+
+// GoroutineManagerOption configures a goroutine manager
+type GoroutineManagerOption func(*goroutineManagerOptions)
+
+type goroutineManagerOptions struct {
+	name string
+
+	slowThreshold   time.Duration
+	onSlowGoroutine func(info GoroutineInfo, stack []byte)
+
+	stopError error
+
+	cleanupTimeout time.Duration
+
+	logger Logger
+
+	clock Clock
+
+	restartBurst    int
+	restartInterval time.Duration
+
+	waitDumpAfter  time.Duration
+	waitDumpWriter io.Writer
+
+	tagLimits map[string]int
+
+	statsRecorder StatsRecorder
+
+	admissionPolicy     *AdmissionPolicy
+	onAdmissionExceeded func(usage AdmissionUsage)
+
+	synchronous bool
+	dryRun      bool
+
+	traceWriter io.Writer
+
+	panicArtifactDir string
+
+	panicProfiles    bool
+	panicProfileHeap bool
+	onPanicProfiles  func(info GoroutineInfo, profiles PanicProfiles)
+
+	highWatermark   int
+	onHighWatermark func(running int, exceeded bool)
+
+	propagatePanics bool
+
+	panicRate           int
+	panicRateWindow     time.Duration
+	onPanicRateExceeded func(panics int, window time.Duration)
+
+	goroutineTelemetry bool
+
+	blockingStacks bool
+}
+
+func newGoroutineManagerOptions(opts []GoroutineManagerOption) goroutineManagerOptions {
+	options := goroutineManagerOptions{
+		logger: nopLogger{},
+		clock:  realClock{},
+
+		statsRecorder: nopStatsRecorder{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.name != "" {
+		options.logger = fieldsLogger{options.logger, []any{"manager", options.name}}
+		options.statsRecorder = labeledStatsRecorder{options.statsRecorder, map[string]string{LabelManager: options.name}}
+	}
+
+	return options
+}
+
+// WithName names the goroutine manager, so that processes with many managers
+// produce distinguishable diagnostics. The name is added to log messages with
+// the key "manager", to metrics with LabelManager, to GoroutineInfo and
+// PanicError, and is returned by Name(), e.g. on the manager returned by
+// FromContext().
+func WithName(name string) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.name = name
+	}
+}
+
+// WithBlockingStacks makes Blocking() include the stacks of the goroutines,
+// which requires looking up the runtime ID of each goroutine when it starts
+func WithBlockingStacks() GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.blockingStacks = true
+	}
+}
+
+// WithSlowThreshold calls hook with the goroutine's metadata and current stack
+// if a goroutine runs for longer than threshold. The goroutine is not stopped.
+func WithSlowThreshold(threshold time.Duration, hook func(info GoroutineInfo, stack []byte)) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.slowThreshold = threshold
+		o.onSlowGoroutine = hook
+	}
+}
+
+// WithStopError uses err as the cause of the goroutine context when the
+// goroutines are stopped, instead of a sentinel allocated for each manager.
+// This allows sharing a single well-known sentinel across many managers. The
+// cause still wraps ErrGoroutineStopped.
+func WithStopError(err error) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.stopError = err
+	}
+}
+
+// WithCleanupTimeout sets the timeout of the contexts returned by
+// CleanupContext(), overriding DefaultCleanupTimeout
+func WithCleanupTimeout(timeout time.Duration) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.cleanupTimeout = timeout
+	}
+}
+
+// WithLogger sets the logger used for lifecycle and error logging. By default,
+// nothing is logged.
+func WithLogger(logger Logger) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.logger = logger
+	}
+}
+
+// WithClock sets the clock used by all time-based features, e.g. to use a fake
+// clock in tests. By default, the time package is used.
+func WithClock(clock Clock) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.clock = clock
+	}
+}
+
+// WithRestartBudget limits the restarts of all supervised goroutines to burst
+// restarts at once, after which one restart is allowed every interval. This
+// prevents a correlated outage from multiplying restart attempts across every
+// supervised goroutine. Restarts wait until the budget allows them.
+func WithRestartBudget(burst int, interval time.Duration) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.restartBurst = burst
+		o.restartInterval = interval
+	}
+}
+
+// WithWaitDumpAfter writes the stacks of the remaining foreground goroutines
+// to w if Wait() is still blocked after d, which turns silent shutdown hangs
+// into actionable logs
+func WithWaitDumpAfter(d time.Duration, w io.Writer) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.waitDumpAfter = d
+		o.waitDumpWriter = w
+	}
+}
+
+// WithTagLimit limits the number of goroutines tagged with tag (see
+// WithTags()) that run at once to limit, so that different classes of work
+// have independent caps. Goroutines over the limit are started, but wait for
+// a slot before running their function; if the goroutine context is cancelled
+// while they are waiting, the function doesn't run at all.
+func WithTagLimit(tag string, limit int) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		if o.tagLimits == nil {
+			o.tagLimits = map[string]int{}
+		}
+
+		o.tagLimits[tag] = limit
+	}
+}
+
+// WithStatsRecorder sets the recorder that receives the metrics of the
+// goroutine manager as lifecycle events happen. By default, metrics are only
+// available through Stats().
+func WithStatsRecorder(recorder StatsRecorder) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.statsRecorder = recorder
+	}
+}
+
+// WithAdmissionControl pauses or rejects goroutines that are started while
+// the heap usage or the number of running goroutines exceeds the thresholds
+// of policy, to protect fan-out-heavy services against running out of
+// memory. hook, which may be nil, is called with the usage whenever the
+// thresholds become exceeded, e.g. for alerting. Panic collectors are not
+// subject to admission control.
+func WithAdmissionControl(policy AdmissionPolicy, hook func(usage AdmissionUsage)) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.admissionPolicy = &policy
+		o.onAdmissionExceeded = hook
+	}
+}
+
+// WithSynchronous runs the functions of started goroutines inline in the
+// caller instead of on new goroutines, still recovering and collecting their
+// panics, so that unit tests of code using the manager are deterministic
+// without sleeps or polling. Functions that block until the goroutine context
+// is cancelled, e.g. periodic goroutines and queue workers, block the caller
+// too, and PanicPolicyRepanic re-raises panics in the caller.
+func WithSynchronous() GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.synchronous = true
+	}
+}
+
+// WithDryRun makes start calls record the goroutines they would have started,
+// including their names, tags and call sites, without running their
+// functions, e.g. to validate how goroutines are wired up in tests or for
+// tooling. The recorded goroutines are returned by Launches(). Panic
+// collectors are not affected.
+func WithDryRun() GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithTrace writes all lifecycle events, i.e. starts, finishes, panics, the
+// cancellation of the goroutine context and state transitions, to w as JSON
+// lines, e.g. to reconstruct the shutdown sequence of a production process
+// with ReadTrace() and SummarizeTrace(). Writes are serialized.
+func WithTrace(w io.Writer) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.traceWriter = w
+	}
+}
+
+// WithPanicArtifactDir writes a file per recovered panic to dir, containing
+// the panic value and stack, the goroutine's metadata and a snapshot of the
+// manager's state, for post-mortem collection by ops tooling. The files are
+// named after the time of the panic. dir is created if it doesn't exist;
+// failures to write a file are logged.
+func WithPanicArtifactDir(dir string) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.panicArtifactDir = dir
+	}
+}
+
+// WithPanicProfiles captures a goroutine profile, and a heap profile if heap
+// is set, when a panic is recovered, before the other goroutines are stopped.
+// hook, which may be nil, is called with the goroutine's metadata and the
+// profiles. If WithPanicArtifactDir is set, the profiles are also written to
+// that directory.
+func WithPanicProfiles(heap bool, hook func(info GoroutineInfo, profiles PanicProfiles)) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.panicProfiles = true
+		o.panicProfileHeap = heap
+		o.onPanicProfiles = hook
+	}
+}
+
+// WithGoroutineHighWatermark calls hook when the number of running foreground
+// and background goroutines rises above n, and again once it falls back to n
+// or below, e.g. to catch leaks or runaway fan-out early. exceeded is whether
+// the watermark is exceeded after the crossing. The crossings are also logged.
+func WithGoroutineHighWatermark(n int, hook func(running int, exceeded bool)) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.highWatermark = n
+		o.onHighWatermark = hook
+	}
+}
+
+// WithPanicPropagation adds the errors recovered from panics in a child
+// manager created with NewChild to the errors of its parent as well, wrapped
+// with the child's name, so that the root manager has a view of all failures.
+// Panics of the child's descendants that propagate to the child are
+// propagated further. The parent's goroutines are not stopped.
+func WithPanicPropagation() GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.propagatePanics = true
+	}
+}
+
+// WithPanicRateAlert calls hook when more than rate panics are recovered
+// within a sliding window, e.g. to alert on a spike of panics even if each of
+// them is tolerated by its panic policy. hook is called with the number of
+// panics within the window once the rate becomes exceeded, and again only
+// after a panic is recovered while the rate is no longer exceeded. The alerts
+// are also logged.
+func WithPanicRateAlert(rate int, window time.Duration, hook func(panics int, window time.Duration)) GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.panicRate = rate
+		o.panicRateWindow = window
+		o.onPanicRateExceeded = hook
+	}
+}
+
+// WithGoroutineTelemetry places a logger and a stats recorder derived from the
+// ones of the goroutine manager into each goroutine context, so that the logs
+// and metrics of goroutines are attributable without passing their name
+// around. The logger adds the goroutine's name and ID with the keys
+// "goroutine" and "id", and the recorder adds LabelGoroutine. Goroutines get
+// them with LoggerFromContext() and StatsRecorderFromContext().
+func WithGoroutineTelemetry() GoroutineManagerOption {
+	return func(o *goroutineManagerOptions) {
+		o.goroutineTelemetry = true
+	}
+}
+
+// StartOption configures a goroutine or panic collector
+type StartOption func(*startOptions)
+
+type startOptions struct {
+	name  string
+	group string // Name of the worker group started with StartWorkers, which is used instead of the name in metrics
+	tags  []string
+
+	panicPolicy PanicPolicy
+
+	runtimeErrorPolicy    PanicPolicy
+	hasRuntimeErrorPolicy bool
+
+	overrunError bool
+
+	background bool
+	timeout    time.Duration
+	deadline   time.Time
+
+	forwardTo []*GoroutineManager
+
+	backoff     Backoff
+	maxRestarts int
+	dedupWindow time.Duration
+
+	heartbeatTimeout time.Duration
+
+	stopPriority    int
+	hasStopPriority bool
+
+	detachedDeadline bool
+
+	noRecover bool
+
+	startupTimeout       time.Duration
+	stopOnStartupTimeout bool
+
+	jitter         time.Duration
+	immediateStart bool
+	fixedRate      bool
+}
+
+func newStartOptions(opts []StartOption) startOptions {
+	var options startOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// WithGoroutineName sets a name for the goroutine, which is added to errors
+// recovered from its panics. The readiness of each name is kept for the
+// lifetime of the manager, see WaitReady(), so names should come from a bounded
+// set, e.g. not contain request IDs.
+func WithGoroutineName(name string) StartOption {
+	return func(o *startOptions) {
+		o.name = name
+	}
+}
+
+// WithTags adds tags to the goroutine, which are included in its metadata
+func WithTags(tags ...string) StartOption {
+	return func(o *startOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithPanicPolicy sets the policy to apply if the goroutine panics
+func WithPanicPolicy(policy PanicPolicy) StartOption {
+	return func(o *startOptions) {
+		o.panicPolicy = policy
+	}
+}
+
+// WithRuntimeErrorPolicy sets the policy to apply if the goroutine panics with
+// a runtime.Error (e.g. a nil pointer dereference or an index out of range),
+// overriding the policy set with WithPanicPolicy. Runtime errors usually
+// indicate bugs rather than signaled failures, so it can make sense to
+// escalate them differently.
+func WithRuntimeErrorPolicy(policy PanicPolicy) StartOption {
+	return func(o *startOptions) {
+		o.runtimeErrorPolicy = policy
+		o.hasRuntimeErrorPolicy = true
+	}
+}
+
+// policyFor returns the panic policy to apply for an error
+func (o startOptions) policyFor(err error) PanicPolicy {
+	var runtimeErr runtime.Error
+	if o.hasRuntimeErrorPolicy && errors.As(err, &runtimeErr) {
+		return o.runtimeErrorPolicy
+	}
+
+	return o.panicPolicy
+}
+
+// WithOverrunError collects an error wrapping ErrGoroutineOverran and
+// context.DeadlineExceeded if a goroutine started with a deadline is still
+// running at its deadline
+func WithOverrunError() StartOption {
+	return func(o *startOptions) {
+		o.overrunError = true
+	}
+}
+
+// WithStartupTimeout collects an error wrapping ErrStartupTimeout if the
+// goroutine doesn't call Ready() within timeout after it is started, so that
+// hanging initialization fails visibly. WaitReady() returns the error for the
+// goroutine's name too. The goroutine itself keeps running unless
+// WithStopOnStartupTimeout is set.
+func WithStartupTimeout(timeout time.Duration) StartOption {
+	return func(o *startOptions) {
+		o.startupTimeout = timeout
+	}
+}
+
+// WithStopOnStartupTimeout stops all goroutines of the manager if the
+// goroutine doesn't become ready within its WithStartupTimeout
+func WithStopOnStartupTimeout() StartOption {
+	return func(o *startOptions) {
+		o.stopOnStartupTimeout = true
+	}
+}
+
+// WithBackground makes Go() and StartPeriodicGoroutine() start a goroutine
+// that can't be waited for to finish
+func WithBackground() StartOption {
+	return func(o *startOptions) {
+		o.background = true
+	}
+}
+
+// WithTimeout makes Go() cancel the goroutine's context after timeout
+func WithTimeout(timeout time.Duration) StartOption {
+	return func(o *startOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithDeadline makes Go() cancel the goroutine's context at deadline
+func WithDeadline(deadline time.Time) StartOption {
+	return func(o *startOptions) {
+		o.deadline = deadline
+	}
+}
+
+// WithForwardTo forwards errors recovered from the goroutine's panics to other
+// goroutine managers, e.g. the root manager of an application, in addition to
+// collecting them locally. The other managers collect the error and call their
+// OnPanic hook, but their goroutines are not stopped; the local panic policy
+// still applies.
+func WithForwardTo(managers ...*GoroutineManager) StartOption {
+	return func(o *startOptions) {
+		o.forwardTo = append(o.forwardTo, managers...)
+	}
+}
+
+// WithBackoff sets the backoff to wait for before restarting a supervised
+// goroutine
+func WithBackoff(backoff Backoff) StartOption {
+	return func(o *startOptions) {
+		o.backoff = backoff
+	}
+}
+
+// WithMaxRestarts limits how often a supervised goroutine is restarted. Zero
+// means no limit.
+func WithMaxRestarts(restarts int) StartOption {
+	return func(o *startOptions) {
+		o.maxRestarts = restarts
+	}
+}
+
+// WithDedupWindow makes a supervised goroutine remember the tasks it completed
+// with RunOnce() for window, so that they are skipped if it is restarted
+func WithDedupWindow(window time.Duration) StartOption {
+	return func(o *startOptions) {
+		o.dedupWindow = window
+	}
+}
+
+// WithHeartbeatTimeout makes Health() report the goroutine as stale if it
+// doesn't call Heartbeat() with its context at least every timeout
+func WithHeartbeatTimeout(timeout time.Duration) StartOption {
+	return func(o *startOptions) {
+		o.heartbeatTimeout = timeout
+	}
+}
+
+// WithStopPriority assigns the goroutine to a stop priority level. Its context
+// is cancelled by StopInPriorityOrder() together with the other goroutines of
+// the level, after the goroutines of all lower levels finished. It is still
+// cancelled immediately by StopAllGoroutines().
+func WithStopPriority(priority int) StartOption {
+	return func(o *startOptions) {
+		o.stopPriority = priority
+		o.hasStopPriority = true
+	}
+}
+
+// WithDetachedDeadline makes the goroutine's context keep running past a
+// deadline of the parent context of the goroutine manager, e.g. for background
+// work started while handling a request that must outlive the request's
+// deadline. The context still carries the values of the goroutine context and
+// is cancelled by StopAllGoroutines(), panics and cancellation of the parent
+// context. It can't be combined with WithStopPriority, which takes precedence.
+func WithDetachedDeadline() StartOption {
+	return func(o *startOptions) {
+		o.detachedDeadline = true
+	}
+}
+
+// WithNoRecover doesn't recover panics of the goroutine, so that they crash
+// the process with the full runtime stack trace and crash dump, e.g. for
+// workers that must fail fast. The goroutine is still recorded as finished
+// while the panic unwinds.
+func WithNoRecover() StartOption {
+	return func(o *startOptions) {
+		o.noRecover = true
+	}
+}
+
+// WithJitter adds a random delay of up to maxJitter to each interval of a
+// periodic goroutine, so that periodic goroutines of many managers don't run
+// in lockstep
+func WithJitter(maxJitter time.Duration) StartOption {
+	return func(o *startOptions) {
+		o.jitter = maxJitter
+	}
+}
+
+// WithImmediateStart makes a periodic goroutine call its function immediately
+// after starting instead of waiting for the first interval
+func WithImmediateStart() StartOption {
+	return func(o *startOptions) {
+		o.immediateStart = true
+	}
+}
+
+// WithFixedRate makes a periodic goroutine call its function at a fixed rate,
+// measuring the interval from the start of one call to the start of the next
+// one, so that the schedule doesn't drift. Calls that were missed because the
+// function took longer than the interval are skipped.
+func WithFixedRate() StartOption {
+	return func(o *startOptions) {
+		o.fixedRate = true
+	}
+}
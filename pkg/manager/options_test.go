@@ -0,0 +1,420 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicPolicyRecord(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyRecord))
+
+	// Verify the error is collected, but the goroutine context is not done.
+	requireNotBlocked(t, m)
+	requireNotDone(t, m)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestPanicPolicyRecordAndCancelAll(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyRecordAndCancelAll))
+
+	// Verify the error is collected and the goroutine context is done.
+	require.Eventually(t, func() bool {
+		return m.Context().Err() != nil
+	}, 100*time.Millisecond, time.Millisecond)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestPanicPolicyRepanic(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	recovered := func() (recovered any) {
+		defer func() {
+			recovered = recover()
+		}()
+
+		defer m.CreateBackgroundPanicCollector(WithPanicPolicy(PanicPolicyRepanic))()
+
+		panic(testErr)
+	}()
+
+	// Verify the panic was re-raised after the error was collected.
+	require.Equal(t, testErr, recovered)
+	requireDone(t, m)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestPanicPolicyFatal(t *testing.T) {
+	t.Parallel()
+
+	fatalErrs := make(chan error, 1)
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnFatal: func(err error) {
+			fatalErrs <- err
+		},
+	})
+
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyFatal))
+
+	// Verify the fatal hook is called and the goroutine context is done.
+	require.ErrorIs(t, <-fatalErrs, testErr)
+	require.Eventually(t, func() bool {
+		return m.Context().Err() != nil
+	}, 100*time.Millisecond, time.Millisecond)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestRuntimeErrorPolicy(t *testing.T) {
+	t.Parallel()
+
+	fatalErrs := make(chan error, 1)
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnFatal: func(err error) {
+			fatalErrs <- err
+		},
+	})
+
+	opts := []StartOption{
+		WithPanicPolicy(PanicPolicyRecord),
+		WithRuntimeErrorPolicy(PanicPolicyFatal),
+	}
+
+	// Verify explicit panics use the regular panic policy.
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, opts...)
+	m.Wait()
+	require.Empty(t, fatalErrs)
+	requireNotDone(t, m)
+
+	// Verify runtime errors use the runtime error policy.
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		var values []int
+		_ = values[1]
+	}, opts...)
+	m.Wait()
+
+	var runtimeErr runtime.Error
+	require.ErrorAs(t, <-fatalErrs, &runtimeErr)
+	requireDone(t, m)
+	require.ErrorIs(t, errs, testErr)
+	require.ErrorAs(t, errs, &runtimeErr)
+}
+
+func TestWithSlowThreshold(t *testing.T) {
+	t.Parallel()
+
+	type slow struct {
+		info  GoroutineInfo
+		stack []byte
+	}
+
+	slows := make(chan slow, 2)
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSlowThreshold(20*time.Millisecond, func(info GoroutineInfo, stack []byte) {
+		slows <- slow{info, stack}
+	}))
+
+	// Verify fast goroutines don't call the hook.
+	m.StartForegroundGoroutine(func(_ context.Context) {}, WithGoroutineName("fast"))
+	m.Wait()
+
+	// Verify slow goroutines call the hook with their current stack, but keep
+	// running.
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		slowGoroutineForTest(done)
+	}, WithGoroutineName("slow"))
+
+	s := <-slows
+	require.Equal(t, "slow", s.info.Name)
+	require.GreaterOrEqual(t, s.info.Runtime, 20*time.Millisecond)
+	require.Contains(t, string(s.stack), "slowGoroutineForTest")
+	requireBlocked(t, m)
+
+	close(done)
+	m.Wait()
+	require.Empty(t, slows)
+	require.NoError(t, errs)
+}
+
+func slowGoroutineForTest(done chan any) {
+	<-done
+}
+
+func TestWithStopError(t *testing.T) {
+	t.Parallel()
+
+	errStopped := errors.New("stopped")
+
+	var errs error
+	m1 := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithStopError(errStopped))
+	m2 := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithStopError(errStopped))
+
+	require.ErrorIs(t, m1.GetErrGoroutineStopped(), errStopped)
+
+	m1.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+
+		panic(ctx.Err())
+	})
+
+	// Verify both managers use the shared sentinel as the stop cause
+	for _, m := range []*GoroutineManager{m1, m2} {
+		m.StopAllGoroutines()
+		m.Wait()
+
+		require.ErrorIs(t, context.Cause(m.Context()), errStopped)
+	}
+
+	// Verify the cancellation caused by stopping isn't collected
+	require.NoError(t, errs)
+}
+
+func TestWithForwardTo(t *testing.T) {
+	t.Parallel()
+
+	var panics atomic.Uint64
+	var rootErrs error
+	root := NewGoroutineManager(context.Background(), &rootErrs, GoroutineManagerHooks{
+		OnPanic: func(_ GoroutineInfo, _ *PanicError) {
+			panics.Add(1)
+		},
+	})
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("subsystem"), WithForwardTo(root))
+	m.Wait()
+
+	// Verify the error is collected by both managers.
+	require.ErrorIs(t, errs, testErr)
+	require.ErrorIs(t, rootErrs, testErr)
+	require.Equal(t, uint64(1), panics.Load())
+
+	// Verify the root manager's goroutines keep running.
+	require.NoError(t, root.Context().Err())
+}
+
+func TestWithDetachedDeadline(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), 10*time.Millisecond)
+	defer cancel()
+
+	var errs error
+	m := NewGoroutineManager(parent, &errs, GoroutineManagerHooks{})
+
+	detached := make(chan context.Context, 1)
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		detached <- ctx
+
+		<-ctx.Done()
+	}, WithDetachedDeadline())
+
+	ctx := <-detached
+	<-m.Context().Done()
+
+	// Verify the goroutine keeps running past the parent's deadline, but
+	// keeps the values of the goroutine context.
+	_, ok := ctx.Deadline()
+	require.False(t, ok)
+	require.NoError(t, ctx.Err())
+	require.Equal(t, "value", ctx.Value(key{}))
+	requireBlocked(t, m)
+
+	// Verify the goroutine is still stopped manually.
+	m.StopAllGoroutines()
+	m.Wait()
+	require.ErrorIs(t, context.Cause(ctx), ErrGoroutineStopped)
+	require.NoError(t, errs)
+}
+
+func TestWithDetachedDeadlineParentCancelled(t *testing.T) {
+	t.Parallel()
+
+	parent, cancel := context.WithCancel(context.Background())
+
+	var errs error
+	m := NewGoroutineManager(parent, &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	}, WithDetachedDeadline())
+
+	// Verify cancelling the parent context still stops the goroutine.
+	cancel()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestWithSynchronous(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSynchronous())
+
+	// Verify functions run before the start call returns.
+	var calls []string
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		calls = append(calls, "foreground")
+	})
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		calls = append(calls, "background")
+	})
+	m.Go(func(_ context.Context) {
+		calls = append(calls, "go")
+	})
+	require.Equal(t, []string{"foreground", "background", "go"}, calls)
+
+	// Verify panics are still recovered and collected.
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+	require.ErrorIs(t, errs, testErr)
+	requireDone(t, m)
+
+	m.Wait()
+}
+
+func TestWithGoroutineHighWatermark(t *testing.T) {
+	t.Parallel()
+
+	type crossing struct {
+		running  int
+		exceeded bool
+	}
+
+	var (
+		lock      sync.Mutex
+		crossings []crossing
+	)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithGoroutineHighWatermark(2, func(running int, exceeded bool) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		crossings = append(crossings, crossing{running, exceeded})
+	}))
+
+	release := make(chan struct{})
+	for range 2 {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			<-release
+		})
+	}
+
+	// Verify reaching the watermark doesn't fire the hook.
+	lock.Lock()
+	require.Empty(t, crossings)
+	lock.Unlock()
+
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		<-release
+	})
+
+	lock.Lock()
+	require.Equal(t, []crossing{{3, true}}, crossings)
+	lock.Unlock()
+
+	close(release)
+	m.StopAllGoroutines()
+	m.Wait()
+
+	// Verify the hook fires once more when the count falls back.
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return len(crossings) == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, crossing{2, false}, crossings[1])
+}
+
+func TestWithNoRecover(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithSynchronous())
+
+	// Verify the panic propagates out of the goroutine instead of being collected.
+	require.PanicsWithValue(t, testErr, func() {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			panic(testErr)
+		}, WithNoRecover())
+	})
+
+	m.Wait()
+
+	require.NoError(t, errs)
+	require.Equal(t, uint64(1), m.Stats().Foreground.Finished)
+}
+
+func TestWithName(t *testing.T) {
+	t.Parallel()
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	r := newTestRecorder()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithLogger(logger), WithStatsRecorder(r), WithName("scheduler"))
+	require.Equal(t, "scheduler", m.Name())
+
+	var fromContext string
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		actual, _ := FromContext(ctx)
+		fromContext = actual.Name()
+
+		panic(testErr)
+	}, WithGoroutineName("worker"))
+	m.Wait()
+
+	// Verify the name is included in the context, errors, logs and metrics.
+	require.Equal(t, "scheduler", fromContext)
+
+	var p *PanicError
+	require.ErrorAs(t, errs, &p)
+	require.Equal(t, "scheduler", p.Manager)
+
+	require.Contains(t, buf.String(), `msg="recovered panic in goroutine" manager=scheduler goroutine=worker`)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	require.Equal(t, float64(1), r.counters[MetricPanics+"{schedulerworker}"])
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"sync"
+	"time"
+)
+
+// panicRate tracks the panics recovered within a sliding window
+type panicRate struct {
+	lock sync.Mutex
+
+	rate   int
+	window time.Duration
+
+	times    []time.Time // Times of the panics within the window, oldest first
+	exceeded bool
+}
+
+// record adds a panic at time now. It returns the number of panics within the
+// window and whether the rate has just become exceeded.
+func (p *panicRate) record(now time.Time) (int, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	start := now.Add(-p.window)
+
+	i := 0
+	for i < len(p.times) && !p.times[i].After(start) {
+		i++
+	}
+	p.times = append(p.times[i:], now)
+
+	exceeded := len(p.times) > p.rate
+	alert := exceeded && !p.exceeded
+	p.exceeded = exceeded
+
+	return len(p.times), alert
+}
+
+// recordPanicRate adds a recovered panic to the panic rate set with
+// WithPanicRateAlert and calls its hook if the rate has just become exceeded
+func (m *GoroutineManager) recordPanicRate(now time.Time) {
+	p := m.panicRate
+	if p == nil {
+		return
+	}
+
+	count, alert := p.record(now)
+	if !alert {
+		return
+	}
+
+	m.options.logger.Warn("panic rate exceeded", "panics", count, "window", p.window)
+
+	if hook := m.options.onPanicRateExceeded; hook != nil {
+		m.callHook("OnPanicRateExceeded", func() {
+			hook(count, p.window)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicRateRecord(t *testing.T) {
+	t.Parallel()
+
+	p := &panicRate{rate: 2, window: time.Minute}
+	start := time.Unix(0, 0)
+
+	// Verify the alert is raised once when the rate becomes exceeded.
+	for i, expected := range []bool{false, false, true, false} {
+		count, alert := p.record(start.Add(time.Duration(i) * time.Second))
+		require.Equal(t, i+1, count)
+		require.Equal(t, expected, alert)
+	}
+
+	// Verify panics outside of the window are dropped and the alert re-arms.
+	count, alert := p.record(start.Add(2 * time.Minute))
+	require.Equal(t, 1, count)
+	require.False(t, alert)
+
+	p.record(start.Add(2*time.Minute + time.Second))
+	_, alert = p.record(start.Add(2*time.Minute + 2*time.Second))
+	require.True(t, alert)
+}
+
+func TestWithPanicRateAlert(t *testing.T) {
+	t.Parallel()
+
+	alerts := make(chan int, 1)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithPanicRateAlert(2, time.Hour, func(panics int, window time.Duration) {
+		require.Equal(t, time.Hour, window)
+
+		alerts <- panics
+	}))
+
+	for range 3 {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			panic(testErr)
+		}, WithPanicPolicy(PanicPolicyRecord))
+		m.Wait()
+	}
+
+	require.Equal(t, 3, <-alerts)
+	require.Len(t, PanicsFrom(errs), 3)
+}
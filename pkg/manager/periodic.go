@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Starts a goroutine that can be waited for to finish and calls fn every
+// interval until the goroutine context is cancelled. If fn panics, the
+// goroutine stops and the panic is collected like for any other goroutine.
+//
+// By default, the first call happens after interval and the interval is
+// measured from the end of one call to the start of the next one (fixed
+// delay). Use WithImmediateStart and WithFixedRate to change this. With
+// WithBackground, the goroutine can't be waited for to finish. Like
+// time.NewTicker, it panics if interval is not positive.
+func (m *GoroutineManager) StartPeriodicGoroutine(interval time.Duration, fn func(context.Context), opts ...StartOption) {
+	if interval <= 0 {
+		panic("non-positive interval for StartPeriodicGoroutine")
+	}
+
+	options := newStartOptions(opts)
+
+	start := m.StartForegroundGoroutine
+	if options.background {
+		start = m.StartBackgroundGoroutine
+	}
+
+	start(func(ctx context.Context) {
+		clock := m.options.clock
+
+		next := clock.Now()
+		if !options.immediateStart {
+			next = next.Add(interval)
+		}
+
+		timer := clock.NewTimer(next.Sub(clock.Now()) + options.nextJitter())
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-timer.C():
+				fn(ctx)
+
+				now := clock.Now()
+				if options.fixedRate {
+					// Skip the calls that were missed because fn took longer than interval
+					next = next.Add(interval)
+					if missed := now.Sub(next); missed > 0 {
+						next = next.Add((missed/interval + 1) * interval)
+					}
+				} else {
+					next = now.Add(interval)
+				}
+
+				timer.Reset(next.Sub(now) + options.nextJitter())
+			}
+		}
+	}, opts...)
+}
+
+// nextJitter returns a random jitter to add to the next interval
+func (o startOptions) nextJitter() time.Duration {
+	if o.jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(o.jitter)))
+}
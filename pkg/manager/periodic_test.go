@@ -0,0 +1,153 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodicGoroutine(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var runs atomic.Int64
+	m.StartPeriodicGoroutine(10*time.Millisecond, func(_ context.Context) {
+		runs.Add(1)
+	})
+
+	// Verify the goroutine runs periodically and blocks Wait.
+	require.Eventually(t, func() bool {
+		return runs.Load() >= 3
+	}, time.Second, time.Millisecond)
+	requireBlocked(t, m)
+
+	// Verify the goroutine stops when all goroutines are stopped.
+	m.StopAllGoroutines()
+	m.Wait()
+
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	require.Equal(t, stopped, runs.Load())
+	require.NoError(t, errs)
+}
+
+func TestPeriodicGoroutinePanic(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartPeriodicGoroutine(time.Millisecond, func(_ context.Context) {
+		panic(testErr)
+	})
+
+	// Verify the panic stops the goroutine and is collected.
+	m.Wait()
+	requireDone(t, m)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestPeriodicGoroutineInvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify non-positive intervals are rejected before starting a goroutine.
+	for _, interval := range []time.Duration{0, -time.Second} {
+		require.PanicsWithValue(t, "non-positive interval for StartPeriodicGoroutine", func() {
+			m.StartPeriodicGoroutine(interval, func(_ context.Context) {}, WithFixedRate())
+		})
+	}
+	require.Zero(t, m.Stats().Foreground.Started)
+
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestWithJitter(t *testing.T) {
+	t.Parallel()
+
+	options := newStartOptions([]StartOption{WithJitter(10 * time.Millisecond)})
+
+	for i := 0; i < 100; i++ {
+		jitter := options.nextJitter()
+
+		require.GreaterOrEqual(t, jitter, time.Duration(0))
+		require.Less(t, jitter, 10*time.Millisecond)
+	}
+
+	require.Zero(t, newStartOptions(nil).nextJitter())
+}
+
+func TestWithImmediateStart(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	runs := make(chan time.Time, 1)
+	started := time.Now()
+	m.StartPeriodicGoroutine(time.Hour, func(_ context.Context) {
+		runs <- time.Now()
+	}, WithImmediateStart())
+
+	// Verify the first call doesn't wait for the interval.
+	require.WithinDuration(t, started, <-runs, 100*time.Millisecond)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestPeriodicGoroutineSchedule(t *testing.T) {
+	t.Parallel()
+
+	const (
+		interval = 40 * time.Millisecond
+		duration = 30 * time.Millisecond
+		calls    = 4
+	)
+
+	// measure returns the time from starting the goroutine to the start of the
+	// last call. Measuring from the first call instead is flaky, since a late
+	// first call shortens the time to the calls scheduled at a fixed rate.
+	measure := func(opts ...StartOption) time.Duration {
+		var errs error
+		m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+		var (
+			last time.Time
+			runs int
+		)
+		start := time.Now()
+		m.StartPeriodicGoroutine(interval, func(_ context.Context) {
+			runs++
+			if runs == calls {
+				last = time.Now()
+				m.StopAllGoroutines()
+
+				return
+			}
+
+			time.Sleep(duration)
+		}, opts...)
+		m.Wait()
+		require.NoError(t, errs)
+
+		return last.Sub(start)
+	}
+
+	// With a fixed delay, the duration of each call adds to the interval.
+	require.GreaterOrEqual(t, measure(), calls*interval+(calls-1)*duration)
+
+	// With a fixed rate, calls start every interval.
+	fixedRate := measure(WithFixedRate())
+	require.GreaterOrEqual(t, fixedRate, calls*interval)
+	require.Less(t, fixedRate, calls*interval+(calls-1)*duration)
+}
@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StartupPhase is a group of goroutines started by StartInPhases() that have
+// to become ready before the next phase is started
+type StartupPhase struct {
+	Name       string             // Name of the phase, which is added to its errors
+	Timeout    time.Duration      // How long the goroutines of the phase may take to call Ready(), or no limit if zero
+	Goroutines []StartupGoroutine // Goroutines to start in the phase
+}
+
+// StartupGoroutine is a foreground goroutine started in a StartupPhase
+type StartupGoroutine struct {
+	Name    string                // Name of the goroutine, or the phase and its index, e.g. "db[0]", if empty
+	Fn      func(context.Context) // Function of the goroutine, which has to call Ready() once it is initialized
+	Options []StartOption         // Options to start the goroutine with
+}
+
+// StartInPhases starts the goroutines of each phase in order, waiting for all
+// goroutines of a phase to call Ready() before starting the next one, e.g. to
+// start the database connections before the servers using them. This moves
+// the ordering of the startup into the manager like StopInPriorityOrder() does
+// for the shutdown.
+//
+// If goroutines of a phase finish without becoming ready or don't become ready
+// within the phase's timeout, the later phases aren't started, and their
+// errors are joined, collected and returned. If ctx is done first, ctx's error
+// is returned. The goroutines that were started keep running in both cases.
+func (m *GoroutineManager) StartInPhases(ctx context.Context, phases ...StartupPhase) error {
+	for _, phase := range phases {
+		if err := m.startPhase(ctx, phase); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startPhase starts the goroutines of a phase and waits for them to be ready
+func (m *GoroutineManager) startPhase(ctx context.Context, phase StartupPhase) error {
+	names := make([]string, 0, len(phase.Goroutines))
+	for i, g := range phase.Goroutines {
+		name := g.Name
+		if name == "" {
+			name = fmt.Sprintf("%v[%v]", phase.Name, i)
+		}
+		names = append(names, name)
+
+		m.StartForegroundGoroutine(g.Fn, append(g.Options[:len(g.Options):len(g.Options)], WithGoroutineName(name))...)
+	}
+
+	waitCtx := ctx
+	if phase.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = withDeadline(m.options.clock, ctx, m.options.clock.Now().Add(phase.Timeout))
+		defer cancel()
+	}
+
+	var errs []error
+	for _, name := range names {
+		err := m.WaitReady(waitCtx, name)
+		switch {
+		case err == nil:
+			continue
+
+		case ctx.Err() != nil:
+			return ctx.Err()
+
+		case errors.Is(err, context.DeadlineExceeded):
+			err = fmt.Errorf("%v: %w within %v", name, ErrStartupTimeout, phase.Timeout)
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("startup phase %v: %w", phase.Name, errors.Join(errs...))
+
+	m.options.logger.Error("startup phase failed", "phase", phase.Name, "error", err)
+
+	m.collectError(err)
+
+	return err
+}
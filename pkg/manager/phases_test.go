@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartInPhases(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		lock    sync.Mutex
+		started []string
+	)
+	serve := func(name string) func(context.Context) {
+		return func(ctx context.Context) {
+			lock.Lock()
+			started = append(started, name)
+			lock.Unlock()
+
+			Ready(ctx)
+
+			<-ctx.Done()
+		}
+	}
+
+	// Verify each phase is started once the previous one is ready.
+	require.NoError(t, m.StartInPhases(context.Background(), StartupPhase{
+		Name: "storage",
+		Goroutines: []StartupGoroutine{
+			{Name: "db", Fn: serve("db")},
+			{Name: "cache", Fn: serve("cache")},
+		},
+	}, StartupPhase{
+		Name:    "servers",
+		Timeout: time.Hour,
+		Goroutines: []StartupGoroutine{
+			{Fn: serve("http")},
+		},
+	}))
+
+	require.ElementsMatch(t, []string{"db", "cache"}, started[:2])
+	require.Equal(t, "http", started[2])
+
+	// Verify unnamed goroutines are named after their phase.
+	require.NoError(t, m.WaitReady(context.Background(), "servers[0]"))
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestStartInPhasesFailure(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	started := false
+
+	// Verify the errors of a phase are joined and later phases aren't started.
+	err := m.StartInPhases(context.Background(), StartupPhase{
+		Name:    "storage",
+		Timeout: 10 * time.Millisecond,
+		Goroutines: []StartupGoroutine{
+			{Name: "db", Fn: func(_ context.Context) {}},
+			{Name: "cache", Fn: func(ctx context.Context) {
+				<-ctx.Done()
+			}},
+		},
+	}, StartupPhase{
+		Name: "servers",
+		Goroutines: []StartupGoroutine{
+			{Name: "http", Fn: func(_ context.Context) {
+				started = true
+			}},
+		},
+	})
+	require.ErrorIs(t, err, ErrNotReady)
+	require.ErrorIs(t, err, ErrStartupTimeout)
+	require.EqualError(t, err, "startup phase storage: db: goroutine finished without becoming ready\ncache: goroutine didn't become ready within 10ms")
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.False(t, started)
+	require.ErrorIs(t, errs, ErrStartupTimeout)
+}
+
+func TestStartInPhasesContext(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Verify ctx's error is returned without being collected.
+	require.ErrorIs(t, m.StartInPhases(ctx, StartupPhase{
+		Name: "storage",
+		Goroutines: []StartupGoroutine{
+			{Name: "db", Fn: func(ctx context.Context) {
+				<-ctx.Done()
+			}},
+		},
+	}), context.Canceled)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
@@ -0,0 +1,147 @@
+package manager
+
+import (
+	"context"
+	"sync"
+)
+
+// StageFunc processes an item of a pipeline stage. Results are passed to the
+// next stage by calling emit any number of times; emit returns false if the
+// goroutine context was cancelled, in which case the function should return.
+type StageFunc func(ctx context.Context, item any, emit func(result any) bool)
+
+type pipelineStage struct {
+	workers int
+	fn      StageFunc
+	opts    []StartOption
+}
+
+// Pipeline is a chain of stages connected by channels. Each stage's workers
+// run as foreground goroutines of a goroutine manager.
+type Pipeline struct {
+	m      *GoroutineManager
+	stages []pipelineStage
+}
+
+// Pipeline creates a new, empty pipeline
+func (m *GoroutineManager) Pipeline() *Pipeline {
+	return &Pipeline{
+		m: m,
+	}
+}
+
+// Stage adds a stage with the given number of workers to the pipeline
+func (p *Pipeline) Stage(workers int, fn StageFunc, opts ...StartOption) *Pipeline {
+	p.stages = append(p.stages, pipelineStage{workers, fn, opts})
+
+	return p
+}
+
+// Run starts the workers of all stages, feeds the items from in into the first
+// stage and returns the channel receiving the results of the last stage.
+//
+// A stage's output channel is closed once all of its workers have returned,
+// which happens if its input channel is closed, the goroutine context is
+// cancelled or the workers panicked. Panics are collected like for any other
+// goroutine, so with the default panic policy a panic in one stage stops all
+// stages.
+func (p *Pipeline) Run(in <-chan any) <-chan any {
+	for _, stage := range p.stages {
+		in = p.runStage(stage, in)
+	}
+
+	return in
+}
+
+func (p *Pipeline) runStage(stage pipelineStage, in <-chan any) <-chan any {
+	var (
+		out = make(chan any)
+		wg  sync.WaitGroup
+	)
+
+	for i := 0; i < stage.workers; i++ {
+		wg.Add(1)
+
+		p.m.StartForegroundGoroutine(func(ctx context.Context) {
+			defer wg.Done()
+
+			emit := func(result any) bool {
+				select {
+				case <-ctx.Done():
+					return false
+
+				case out <- result:
+					return true
+				}
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+
+				case item, ok := <-in:
+					if !ok {
+						return
+					}
+
+					stage.fn(ctx, item, emit)
+				}
+			}
+		}, stage.opts...)
+	}
+
+	p.m.StartBackgroundGoroutine(func(_ context.Context) {
+		wg.Wait()
+
+		close(out)
+	})
+
+	return out
+}
+
+// Merge forwards the values received from chans into one channel. Each input
+// channel is forwarded by a foreground goroutine of m, which returns once the
+// input channel is closed or the goroutine context is cancelled. The output
+// channel is closed once all forwarding goroutines have returned.
+func Merge[T any](m *GoroutineManager, chans ...<-chan T) <-chan T {
+	var (
+		out = make(chan T)
+		wg  sync.WaitGroup
+	)
+
+	for _, in := range chans {
+		wg.Add(1)
+
+		m.StartForegroundGoroutine(func(ctx context.Context) {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+
+				case value, ok := <-in:
+					if !ok {
+						return
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+
+					case out <- value:
+					}
+				}
+			}
+		})
+	}
+
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		wg.Wait()
+
+		close(out)
+	})
+
+	return out
+}
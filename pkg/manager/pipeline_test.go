@@ -0,0 +1,160 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	in := make(chan any)
+	out := m.Pipeline().
+		Stage(3, func(_ context.Context, item any, emit func(any) bool) {
+			// Filter out odd numbers
+			if item.(int)%2 == 0 {
+				emit(item)
+			}
+		}).
+		Stage(2, func(_ context.Context, item any, emit func(any) bool) {
+			// Emit each number twice
+			if emit(item.(int) * 10) {
+				emit(item.(int)*10 + 1)
+			}
+		}).
+		Run(in)
+
+	go func() {
+		defer close(in)
+
+		for i := 0; i < 6; i++ {
+			in <- i
+		}
+	}()
+
+	var results []int
+	for result := range out {
+		results = append(results, result.(int))
+	}
+	sort.Ints(results)
+
+	// Verify all items were processed and the output channel was closed.
+	require.Equal(t, []int{0, 1, 20, 21, 40, 41}, results)
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestPipelineStop(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	in := make(chan any)
+	out := m.Pipeline().
+		Stage(2, func(_ context.Context, item any, emit func(any) bool) {
+			emit(item)
+		}).
+		Stage(2, func(_ context.Context, item any, emit func(any) bool) {
+			emit(item)
+		}).
+		Run(in)
+
+	in <- 1
+	require.Equal(t, 1, <-out)
+
+	// Verify all stages stop and the output channel is closed without closing
+	// the input channel.
+	m.StopAllGoroutines()
+	for range out {
+	}
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestPipelinePanic(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	in := make(chan any)
+	out := m.Pipeline().
+		Stage(1, func(_ context.Context, item any, emit func(any) bool) {
+			emit(item)
+		}).
+		Stage(1, func(_ context.Context, item any, emit func(any) bool) {
+			panic(testErr)
+		}).
+		Run(in)
+
+	in <- 1
+
+	// Verify a panic in one stage stops all stages.
+	for range out {
+	}
+	m.Wait()
+	requireDone(t, m)
+	require.ErrorIs(t, errs, testErr)
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	a := make(chan int)
+	b := make(chan int)
+	out := Merge(m, a, b)
+
+	go func() {
+		defer close(a)
+
+		a <- 1
+		a <- 2
+	}()
+
+	go func() {
+		defer close(b)
+
+		b <- 3
+	}()
+
+	var values []int
+	for value := range out {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+
+	// Verify all values were forwarded and the output channel was closed.
+	require.Equal(t, []int{1, 2, 3}, values)
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestMergeStop(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	a := make(chan int)
+	out := Merge(m, a, make(chan int))
+
+	a <- 1
+	require.Equal(t, 1, <-out)
+
+	// Verify the output channel is closed once all goroutines are stopped.
+	m.StopAllGoroutines()
+	_, ok := <-out
+	require.False(t, ok)
+	m.Wait()
+	require.NoError(t, errs)
+}
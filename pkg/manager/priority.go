@@ -0,0 +1,138 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// stopLevel is a stop priority level with its own context, which is derived
+// from the goroutine context
+type stopLevel struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	running int
+	idle    chan struct{} // Closed while no goroutines of the level are running
+}
+
+// stopLevels holds the stop priority levels of a goroutine manager
+type stopLevels struct {
+	lock   sync.Mutex
+	levels map[int]*stopLevel
+}
+
+func newStopLevels() *stopLevels {
+	return &stopLevels{
+		levels: map[int]*stopLevel{},
+	}
+}
+
+// level returns the level for a priority, creating it if necessary. It must be
+// called with the lock held.
+func (s *stopLevels) level(ctx context.Context, priority int) *stopLevel {
+	level, ok := s.levels[priority]
+	if !ok {
+		idle := make(chan struct{})
+		close(idle)
+
+		level = &stopLevel{
+			idle: idle,
+		}
+		level.ctx, level.cancel = context.WithCancelCause(ctx)
+
+		s.levels[priority] = level
+	}
+
+	return level
+}
+
+// add registers a running goroutine with the level of a priority
+func (s *stopLevels) add(ctx context.Context, priority int) *stopLevel {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	level := s.level(ctx, priority)
+	if level.running == 0 {
+		level.idle = make(chan struct{})
+	}
+	level.running++
+
+	return level
+}
+
+// remove unregisters a finished goroutine from its level
+func (s *stopLevels) remove(level *stopLevel) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	level.running--
+	if level.running == 0 {
+		close(level.idle)
+	}
+}
+
+// sorted returns the levels ordered by ascending priority
+func (s *stopLevels) sorted() []*stopLevel {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	priorities := make([]int, 0, len(s.levels))
+	for priority := range s.levels {
+		priorities = append(priorities, priority)
+	}
+	sort.Ints(priorities)
+
+	levels := make([]*stopLevel, 0, len(priorities))
+	for _, priority := range priorities {
+		levels = append(levels, s.levels[priority])
+	}
+
+	return levels
+}
+
+// idle returns the channel that is closed while no goroutines of a level are
+// running
+func (s *stopLevels) idle(level *stopLevel) <-chan struct{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return level.idle
+}
+
+// StopInPriorityOrder quiesces the goroutine manager and stops the goroutines
+// started with WithStopPriority level by level, from the lowest to the highest
+// priority, waiting for the goroutines of each level to finish before
+// cancelling the next one. Afterwards, all other goroutines are stopped like
+// with StopAllGoroutines(). If ctx is cancelled before all levels finished, the
+// remaining goroutines are stopped immediately and ctx's error is returned.
+//
+// This allows ordering a shutdown within one manager, e.g. stopping HTTP
+// servers before the workers that they enqueue tasks for.
+//
+// Child managers created with NewChild are stopped in their own priority order
+// first, most recently created first, before the levels of m.
+func (m *GoroutineManager) StopInPriorityOrder(ctx context.Context) error {
+	m.Quiesce()
+
+	defer m.StopAllGoroutines()
+
+	for _, child := range m.children.reversed() {
+		if err := child.StopInPriorityOrder(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, level := range m.stopLevels.sorted() {
+		level.cancel(m.errFinished)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-m.stopLevels.idle(level):
+		}
+	}
+
+	return nil
+}
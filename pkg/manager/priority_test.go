@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopInPriorityOrder(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		lock    sync.Mutex
+		stopped []string
+	)
+	start := func(name string, opts ...StartOption) {
+		m.StartForegroundGoroutine(func(ctx context.Context) {
+			<-ctx.Done()
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			stopped = append(stopped, name)
+
+			panic(ctx.Err())
+		}, opts...)
+	}
+
+	start("workers", WithStopPriority(1))
+	start("server", WithStopPriority(0))
+	start("unprioritized")
+
+	// Verify the levels are stopped in order before the other goroutines.
+	require.NoError(t, m.StopInPriorityOrder(context.Background()))
+	m.Wait()
+
+	require.Equal(t, []string{"server", "workers", "unprioritized"}, stopped)
+
+	// Verify the cancellations aren't collected as errors.
+	require.NoError(t, errs)
+}
+
+func TestStopInPriorityOrderTimeout(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		<-done
+	}, WithStopPriority(0))
+
+	stopped := make(chan any)
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	}, WithStopPriority(1))
+
+	// Verify the remaining goroutines are stopped if ctx is cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, m.StopInPriorityOrder(ctx), context.DeadlineExceeded)
+	<-stopped
+
+	close(done)
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestStopAllGoroutinesWithPriorities(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	}, WithStopPriority(5))
+
+	// Verify StopAllGoroutines() still stops all levels at once.
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+)
+
+// PanicProfiles are the pprof profiles captured when a panic is recovered, in
+// the gzipped protobuf format read by `go tool pprof`
+type PanicProfiles struct {
+	Goroutine []byte // Stacks of all goroutines of the process
+	Heap      []byte // Heap allocations, or nil if not requested
+}
+
+// capturePanicProfiles captures the profiles configured with WithPanicProfiles
+func (m *GoroutineManager) capturePanicProfiles() PanicProfiles {
+	profiles := PanicProfiles{
+		Goroutine: m.captureProfile("goroutine"),
+	}
+
+	if m.options.panicProfileHeap {
+		profiles.Heap = m.captureProfile("heap")
+	}
+
+	return profiles
+}
+
+// captureProfile writes the pprof profile with the given name, or returns nil
+// and logs the error if that fails
+func (m *GoroutineManager) captureProfile(name string) []byte {
+	var buf bytes.Buffer
+	if err := pprof.Lookup(name).WriteTo(&buf, 0); err != nil {
+		m.options.logger.Error("could not capture profile", "profile", name, "error", err)
+
+		return nil
+	}
+
+	return buf.Bytes()
+}
+
+// handlePanicProfiles captures the profiles for the panic e recovered from
+// goroutine g, writes them to the panic artifact directory if one is set and
+// calls the profile hook
+func (m *GoroutineManager) handlePanicProfiles(g *goroutine, e *PanicError) {
+	profiles := m.capturePanicProfiles()
+
+	if dir := m.options.panicArtifactDir; dir != "" {
+		prefix := "panic-" + e.Time.UTC().Format(panicArtifactTimeFormat) + "-"
+
+		m.writePanicProfile(dir, prefix+"goroutine-*.pb.gz", profiles.Goroutine)
+		m.writePanicProfile(dir, prefix+"heap-*.pb.gz", profiles.Heap)
+	}
+
+	if hook := m.options.onPanicProfiles; hook != nil {
+		m.callHook("OnPanicProfiles", func() {
+			hook(g.info(e.Time), profiles)
+		})
+	}
+}
+
+// writePanicProfile writes a captured profile to a new file in dir named
+// after pattern, as with os.CreateTemp
+func (m *GoroutineManager) writePanicProfile(dir, pattern string, profile []byte) {
+	if profile == nil {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		m.options.logger.Error("could not create panic artifact directory", "dir", dir, "error", err)
+
+		return
+	}
+
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		m.options.logger.Error("could not create panic profile", "file", filepath.Join(dir, pattern), "error", err)
+
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(profile); err != nil {
+		m.options.logger.Error("could not write panic profile", "file", f.Name(), "error", err)
+	}
+}
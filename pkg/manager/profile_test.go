@@ -0,0 +1,71 @@
+package manager
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPanicProfiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	profiles := make(chan PanicProfiles, 1)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithPanicArtifactDir(dir), WithPanicProfiles(true, func(info GoroutineInfo, p PanicProfiles) {
+		require.Equal(t, "panicker", info.Name)
+
+		profiles <- p
+	}))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("panicker"))
+	m.Wait()
+
+	p := <-profiles
+
+	// Verify the profiles are gzipped pprof profiles.
+	for _, profile := range [][]byte{p.Goroutine, p.Heap} {
+		r, err := gzip.NewReader(bytes.NewReader(profile))
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NotEmpty(t, data)
+	}
+
+	// Verify the profiles are written next to the panic artifact.
+	for _, pattern := range []string{"panic-*-goroutine-*.pb.gz", "panic-*-heap-*.pb.gz"} {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+	}
+}
+
+func TestWithPanicProfilesWithoutHeap(t *testing.T) {
+	t.Parallel()
+
+	profiles := make(chan PanicProfiles, 1)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithPanicProfiles(false, func(_ GoroutineInfo, p PanicProfiles) {
+		profiles <- p
+	}))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+	m.Wait()
+
+	p := <-profiles
+	require.NotEmpty(t, p.Goroutine)
+	require.Nil(t, p.Heap)
+}
@@ -0,0 +1,487 @@
+package manager
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrQueueFull    = errors.New("queue is full")    // Returned if a task can't be enqueued because the queue is full
+	ErrQueueStopped = errors.New("queue is stopped") // Returned if a task can't be enqueued because the goroutine manager was quiesced or the goroutine context was cancelled
+)
+
+// QueueOption configures a queue
+type QueueOption func(*queueOptions)
+
+type queueOptions struct {
+	startOptions []StartOption
+	adaptive     *AdaptiveConcurrency
+
+	stealInterval time.Duration
+	ordered       bool // Tasks must run in the order they were enqueued, so they can't be stolen
+
+	journal        Journal
+	journalHandler func(ctx context.Context, payload []byte)
+}
+
+// WithWorkerStartOptions sets the options for the queue's worker goroutines
+// and the panic collectors of its tasks
+func WithWorkerStartOptions(opts ...StartOption) QueueOption {
+	return func(o *queueOptions) {
+		o.startOptions = append(o.startOptions, opts...)
+	}
+}
+
+// TaskOption configures a queued task
+type TaskOption func(*queuedTask)
+
+// WithPriority sets the priority of a task. Queued tasks with a higher
+// priority are started before queued tasks with a lower priority; tasks with
+// the same priority are started in the order they were enqueued. Tasks that
+// are already running are not affected. The default priority is 0.
+func WithPriority(priority int) TaskOption {
+	return func(t *queuedTask) {
+		t.priority = priority
+	}
+}
+
+// WithGroup adds a task to a task group. Queued tasks of different groups are
+// started in proportion to the groups' weights, so that one group can't starve
+// the others. Groups that were not registered with RegisterGroup have a weight
+// of 1. By default, tasks are added to the group "".
+func WithGroup(name string) TaskOption {
+	return func(t *queuedTask) {
+		t.groupName = name
+	}
+}
+
+type queuedTask struct {
+	fn        func(context.Context)
+	enqueued  time.Time
+	priority  int
+	groupName string
+	group     *taskGroup
+	seq       uint64
+}
+
+// strideBase is divided by a group's weight to get the group's stride
+const strideBase = 1 << 20
+
+// taskGroup holds the queued tasks of a group. Groups are scheduled with
+// stride scheduling: each time a task of a group is started, the group's pass
+// is advanced by its stride, and the group with the lowest pass goes next.
+type taskGroup struct {
+	weight int
+	pass   uint64
+	tasks  taskHeap
+
+	enqueued  uint64
+	processed uint64
+}
+
+func (g *taskGroup) stride() uint64 {
+	return strideBase / uint64(g.weight)
+}
+
+// taskHeap orders tasks by descending priority and ascending sequence number
+type taskHeap []*queuedTask
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x any) { *h = append(*h, x.(*queuedTask)) }
+
+func (h *taskHeap) Pop() any {
+	old := *h
+	n := len(old)
+
+	task := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return task
+}
+
+// Queue is a bounded queue of tasks that are run by a fixed number of
+// foreground goroutines of a goroutine manager. Panics in tasks are collected
+// like for any other goroutine; if the panic policy doesn't stop all
+// goroutines, the worker continues with the next task.
+type Queue struct {
+	m       *GoroutineManager
+	name    string
+	workers int
+	options queueOptions
+
+	slots chan struct{} // Holds a token for each queued task, limiting the queue to its capacity
+	ready chan struct{} // Holds a token for each queued task that workers can take
+
+	lock      sync.Mutex
+	groups    map[string]*taskGroup
+	depth     int
+	pass      uint64 // Pass of the group of the last started task
+	enqueued  uint64
+	rejected  uint64
+	processed uint64
+	stolen    uint64
+	waitTimes DurationHistogram
+
+	active        int           // Number of workers that may run tasks at once
+	activeChanged chan struct{} // Closed when active changes
+	window        queueWindow
+}
+
+// queueWindow contains the tasks that finished since the last adjustment of
+// an adaptive queue
+type queueWindow struct {
+	tasks   int
+	panics  int
+	latency time.Duration
+}
+
+// QueueStats contains statistics about a queue
+type QueueStats struct {
+	Workers   int               // Number of worker goroutines
+	Active    int               // Number of workers that may run tasks at once, which is less than Workers if WithAdaptiveConcurrency shrank them
+	Capacity  int               // Maximum number of queued tasks
+	Depth     int               // Number of currently queued tasks
+	Enqueued  uint64            // Total number of enqueued tasks
+	Rejected  uint64            // Total number of tasks rejected because the queue was full
+	Processed uint64            // Total number of tasks that were run to completion
+	Stolen    uint64            // Total number of tasks that were run by workers of other queues, see WithWorkStealing
+	WaitTimes DurationHistogram // Time tasks spent in the queue before a worker started them
+
+	Groups map[string]QueueGroupStats // Statistics of the task groups by name
+}
+
+// QueueGroupStats contains statistics about a task group of a queue
+type QueueGroupStats struct {
+	Weight    int    // Weight of the group
+	Depth     int    // Number of currently queued tasks of the group
+	Enqueued  uint64 // Total number of enqueued tasks of the group
+	Processed uint64 // Total number of tasks of the group that were run to completion
+}
+
+// NewQueue creates a queue holding up to capacity tasks and starts its
+// workers. The name identifies the queue in statistics and should be unique
+// within the goroutine manager; it is also used as the default goroutine name
+// of the workers. Workers and capacity below 1 are treated as 1, since a queue
+// without workers never runs its tasks and one without capacity can't hold
+// them.
+func (m *GoroutineManager) NewQueue(name string, workers, capacity int, opts ...QueueOption) *Queue {
+	options := queueOptions{
+		startOptions: []StartOption{WithGoroutineName(name)},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return m.newQueue(name, workers, capacity, options)
+}
+
+func (m *GoroutineManager) newQueue(name string, workers, capacity int, options queueOptions) *Queue {
+	workers = max(workers, 1)
+	capacity = max(capacity, 1)
+
+	active := workers
+	if options.adaptive != nil {
+		config := *options.adaptive
+		active = config.normalize(workers)
+		workers = config.MaxWorkers
+
+		options.adaptive = &config
+	}
+
+	q := &Queue{
+		m:       m,
+		name:    name,
+		workers: workers,
+		options: options,
+
+		slots: make(chan struct{}, capacity),
+		ready: make(chan struct{}, capacity),
+
+		groups:    map[string]*taskGroup{},
+		waitTimes: newDurationHistogram(),
+
+		active:        active,
+		activeChanged: make(chan struct{}),
+	}
+
+	m.stats.addQueue(name, q)
+
+	for i := 0; i < workers; i++ {
+		m.StartForegroundGoroutine(func(ctx context.Context) {
+			q.work(ctx, i)
+		}, options.startOptions...)
+	}
+
+	if options.adaptive != nil {
+		m.StartPeriodicGoroutine(options.adaptive.Interval, q.adapt, WithGoroutineName(name+" controller"))
+	}
+
+	if options.journal != nil {
+		q.replay()
+	}
+
+	return q
+}
+
+// RegisterGroup sets the weight of a task group. A group with twice the weight
+// of another group gets twice as many of its queued tasks started. Weights
+// below 1 are treated as 1.
+func (q *Queue) RegisterGroup(name string, weight int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.group(name).weight = max(weight, 1)
+}
+
+// Enqueue adds a task to the queue, blocking while the queue is full. It
+// returns ctx.Err() if ctx is cancelled and ErrQueueStopped if the goroutine
+// manager is quiesced or the goroutine context is cancelled before the task
+// could be enqueued.
+func (q *Queue) Enqueue(ctx context.Context, fn func(context.Context), opts ...TaskOption) error {
+	if err := q.acquire(ctx); err != nil {
+		return err
+	}
+
+	q.push(fn, opts)
+
+	return nil
+}
+
+// TryEnqueue adds a task to the queue without blocking. It returns
+// ErrQueueFull if the queue is full and ErrQueueStopped if the goroutine
+// manager was quiesced or the goroutine context was cancelled.
+func (q *Queue) TryEnqueue(fn func(context.Context), opts ...TaskOption) error {
+	if err := q.tryAcquire(); err != nil {
+		return err
+	}
+
+	q.push(fn, opts)
+
+	return nil
+}
+
+// acquire acquires a slot for a task, blocking while the queue is full
+func (q *Queue) acquire(ctx context.Context) error {
+	if q.stopped() {
+		return ErrQueueStopped
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.m.quiesced:
+		return ErrQueueStopped
+	case <-q.m.internalCtx.Done():
+		return ErrQueueStopped
+	}
+}
+
+// tryAcquire acquires a slot for a task without blocking
+func (q *Queue) tryAcquire() error {
+	if q.stopped() {
+		return ErrQueueStopped
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	default:
+		q.lock.Lock()
+		q.rejected++
+		q.lock.Unlock()
+
+		return ErrQueueFull
+	}
+}
+
+// Stats returns a snapshot of the statistics of the queue
+func (q *Queue) Stats() QueueStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	stats := QueueStats{
+		Workers:   q.workers,
+		Active:    q.active,
+		Capacity:  cap(q.slots),
+		Depth:     q.depth,
+		Enqueued:  q.enqueued,
+		Rejected:  q.rejected,
+		Processed: q.processed,
+		Stolen:    q.stolen,
+		WaitTimes: q.waitTimes.clone(),
+
+		Groups: map[string]QueueGroupStats{},
+	}
+	for name, g := range q.groups {
+		stats.Groups[name] = QueueGroupStats{
+			Weight:    g.weight,
+			Depth:     len(g.tasks),
+			Enqueued:  g.enqueued,
+			Processed: g.processed,
+		}
+	}
+
+	return stats
+}
+
+// group returns the task group with the given name, creating it if it doesn't
+// exist. It must be called with the lock held.
+func (q *Queue) group(name string) *taskGroup {
+	g, ok := q.groups[name]
+	if !ok {
+		g = &taskGroup{
+			weight: 1,
+		}
+
+		q.groups[name] = g
+	}
+
+	return g
+}
+
+// stopped returns true if the queue doesn't accept new tasks
+func (q *Queue) stopped() bool {
+	return q.m.isQuiesced() || q.m.internalCtx.Err() != nil
+}
+
+// push adds a task to the queue. The caller must have acquired a slot.
+func (q *Queue) push(fn func(context.Context), opts []TaskOption) {
+	task := &queuedTask{
+		fn:       fn,
+		enqueued: q.m.options.clock.Now(),
+	}
+	for _, opt := range opts {
+		opt(task)
+	}
+
+	q.lock.Lock()
+	task.group = q.group(task.groupName)
+	task.seq = q.enqueued
+
+	// Groups that had no queued tasks don't get credit for the time they
+	// were idle
+	if len(task.group.tasks) == 0 {
+		task.group.pass = max(task.group.pass, q.pass)
+	}
+
+	heap.Push(&task.group.tasks, task)
+	task.group.enqueued++
+	q.depth++
+	q.enqueued++
+	q.lock.Unlock()
+
+	q.ready <- struct{}{}
+}
+
+// pop removes the next task from the queue: the task with the highest
+// priority, and if multiple groups have tasks with that priority, the task of
+// the group with the lowest pass. The caller must have taken a ready token.
+func (q *Queue) pop() *queuedTask {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var next *taskGroup
+	for _, g := range q.groups {
+		if len(g.tasks) == 0 {
+			continue
+		}
+
+		if next == nil ||
+			g.tasks[0].priority > next.tasks[0].priority ||
+			(g.tasks[0].priority == next.tasks[0].priority && g.pass < next.pass) ||
+			(g.tasks[0].priority == next.tasks[0].priority && g.pass == next.pass && g.tasks[0].seq < next.tasks[0].seq) {
+			next = g
+		}
+	}
+
+	task := heap.Pop(&next.tasks).(*queuedTask)
+
+	q.pass = next.pass
+	next.pass += next.stride()
+	q.depth--
+
+	q.waitTimes.observe(q.m.options.clock.Now().Sub(task.enqueued))
+
+	<-q.slots
+
+	return task
+}
+
+// work runs queued tasks as the worker with index i until the goroutine
+// context is cancelled
+func (q *Queue) work(ctx context.Context, i int) {
+	var (
+		steal <-chan time.Time
+		timer Timer
+	)
+	if q.options.stealInterval > 0 {
+		timer = q.m.options.clock.NewTimer(q.options.stealInterval)
+		defer timer.Stop()
+
+		steal = timer.C()
+	}
+
+	for {
+		if !q.waitActive(ctx, i) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-q.ready:
+			q.run(ctx, q.pop())
+
+		case <-steal:
+			// Steal one task at a time, so that the worker gets back to its
+			// own queue quickly, but look again right away after a success
+			if q.steal(ctx) {
+				timer.Reset(0)
+			} else {
+				timer.Reset(q.options.stealInterval)
+			}
+		}
+	}
+}
+
+// run runs a task, collecting its panics
+func (q *Queue) run(ctx context.Context, task *queuedTask) {
+	started := q.m.options.clock.Now()
+
+	// Tasks run on the queue's workers, so they aren't counted as goroutines
+	defer q.m.recoverUntracked(started, q.options.startOptions)()
+
+	panicked := true
+	if q.options.adaptive != nil {
+		defer func() {
+			q.observe(q.m.options.clock.Now().Sub(started), panicked)
+		}()
+	}
+
+	task.fn(ctx)
+
+	panicked = false
+
+	q.lock.Lock()
+	q.processed++
+	task.group.processed++
+	q.lock.Unlock()
+}
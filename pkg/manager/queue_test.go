@@ -0,0 +1,244 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 2, 10)
+
+	var runs atomic.Int64
+	for i := 0; i < 10; i++ {
+		require.NoError(t, q.Enqueue(context.Background(), func(_ context.Context) {
+			runs.Add(1)
+		}))
+	}
+
+	// Verify all tasks are run.
+	require.Eventually(t, func() bool {
+		return runs.Load() == 10
+	}, time.Second, time.Millisecond)
+
+	// Verify the workers block Wait until they are stopped.
+	requireBlocked(t, m)
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.ErrorIs(t, q.Enqueue(context.Background(), func(_ context.Context) {}), ErrQueueStopped)
+	require.ErrorIs(t, q.TryEnqueue(func(_ context.Context) {}), ErrQueueStopped)
+	require.NoError(t, errs)
+}
+
+func TestQueueInvalidSizes(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 0, -1)
+
+	// Verify the sizes are raised to 1, so tasks can be enqueued and are run.
+	stats := q.Stats()
+	require.Equal(t, 1, stats.Workers)
+	require.Equal(t, 1, stats.Capacity)
+
+	done := make(chan struct{})
+	require.NoError(t, q.Enqueue(context.Background(), func(_ context.Context) {
+		close(done)
+	}))
+	<-done
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestQueueBackpressure(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 1, 2)
+
+	started := make(chan any)
+	done := make(chan any)
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+		close(started)
+		<-done
+	}))
+	<-started
+
+	// Fill the queue while the only worker is busy.
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {}))
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {}))
+
+	// Verify non-blocking enqueues fail and blocking enqueues wait.
+	require.ErrorIs(t, q.TryEnqueue(func(_ context.Context) {}), ErrQueueFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, q.Enqueue(ctx, func(_ context.Context) {}), context.DeadlineExceeded)
+
+	// Verify the depth is exposed in the statistics.
+	stats := m.Stats().Queues["test"]
+	require.Equal(t, 1, stats.Workers)
+	require.Equal(t, 2, stats.Capacity)
+	require.Equal(t, 2, stats.Depth)
+	require.Equal(t, uint64(3), stats.Enqueued)
+	require.Equal(t, uint64(1), stats.Rejected)
+
+	// Verify blocked enqueues continue once there is space.
+	enqueued := make(chan error)
+	go func() {
+		enqueued <- q.Enqueue(context.Background(), func(_ context.Context) {})
+	}()
+	close(done)
+	require.NoError(t, <-enqueued)
+
+	require.Eventually(t, func() bool {
+		return m.Stats().Queues["test"].Processed == 4
+	}, time.Second, time.Millisecond)
+
+	stats = m.Stats().Queues["test"]
+	require.Zero(t, stats.Depth)
+	require.Equal(t, uint64(4), stats.WaitTimes.Count)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestQueuePanic(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 1, 2, WithWorkerStartOptions(WithPanicPolicy(PanicPolicyRecord)))
+
+	// Verify the worker continues after a task panicked.
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+		panic(testErr)
+	}))
+
+	done := make(chan any)
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+		close(done)
+	}))
+	<-done
+
+	// Verify tasks aren't counted as goroutines, only the worker is.
+	stats := m.Stats()
+	require.Equal(t, uint64(1), stats.Foreground.Started+stats.Background.Started)
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	panics := PanicsFrom(errs)
+	require.Len(t, panics, 1)
+	require.Equal(t, "test", panics[0].Name)
+	require.ErrorIs(t, panics[0], testErr)
+}
+
+func TestQueuePriority(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 1, 10)
+
+	// Block the only worker so that the following tasks are queued.
+	started := make(chan any)
+	done := make(chan any)
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+		close(started)
+		<-done
+	}, WithPriority(-1)))
+	<-started
+
+	order := make(chan string, 5)
+	enqueue := func(name string, priority int) {
+		require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+			order <- name
+		}, WithPriority(priority)))
+	}
+
+	enqueue("low-1", -1)
+	enqueue("default-1", 0)
+	enqueue("high", 10)
+	enqueue("default-2", 0)
+	enqueue("low-2", -1)
+
+	// Verify the running task isn't preempted and queued tasks are started by
+	// priority, then in the order they were enqueued.
+	close(done)
+
+	var names []string
+	for i := 0; i < 5; i++ {
+		names = append(names, <-order)
+	}
+	require.Equal(t, []string{"high", "default-1", "default-2", "low-1", "low-2"}, names)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestQueueGroups(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 1, 20)
+	q.RegisterGroup("a", 3)
+
+	// Block the only worker so that the following tasks are queued.
+	started := make(chan any)
+	done := make(chan any)
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+		close(started)
+		<-done
+	}))
+	<-started
+
+	// Flood the queue with tasks of group a before enqueuing tasks of group b.
+	order := make(chan string, 16)
+	for _, group := range []string{"a", "b"} {
+		for i := 0; i < 8; i++ {
+			require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+				order <- group
+			}, WithGroup(group)))
+		}
+	}
+
+	stats := m.Stats().Queues["test"].Groups
+	require.Equal(t, QueueGroupStats{Weight: 3, Depth: 8, Enqueued: 8}, stats["a"])
+	require.Equal(t, QueueGroupStats{Weight: 1, Depth: 8, Enqueued: 8}, stats["b"])
+
+	close(done)
+
+	// Verify tasks are started in proportion to the group weights.
+	var groups []string
+	for i := 0; i < 8; i++ {
+		groups = append(groups, <-order)
+	}
+	require.Equal(t, []string{"a", "b", "a", "a", "a", "b", "a", "a"}, groups)
+
+	// Verify the throughput of each group is exposed in the statistics.
+	require.Eventually(t, func() bool {
+		stats := m.Stats().Queues["test"].Groups
+
+		return stats["a"].Processed == 8 && stats["b"].Processed == 8 && stats[""].Processed == 1
+	}, time.Second, time.Millisecond)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
@@ -0,0 +1,231 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotReady       = errors.New("goroutine finished without becoming ready") // Returned by WaitReady() if all goroutines with a name finished without calling Ready()
+	ErrStartupTimeout = errors.New("goroutine didn't become ready")             // Collected and returned by WaitReady() if a goroutine started with WithStartupTimeout doesn't call Ready() in time
+)
+
+type readyKey struct{}
+
+// readyName is the readiness of the goroutines with the same name
+type readyName struct {
+	done    chan struct{} // Closed once a goroutine is ready or all of them finished without becoming ready
+	err     error         // Set before done is closed if no goroutine became ready
+	running int
+}
+
+// resolved returns true if done is closed. The lock of the readiness must be held.
+func (n *readyName) resolved() bool {
+	select {
+	case <-n.done:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// readiness keeps track of the goroutines that signalled they are ready. Names
+// are never removed, since WaitReady() has to return for goroutines that
+// finished before it was called, so the set of names must be bounded.
+type readiness struct {
+	lock  sync.Mutex
+	names map[string]*readyName
+}
+
+func newReadiness() *readiness {
+	return &readiness{
+		names: map[string]*readyName{},
+	}
+}
+
+// get returns the readiness of name, replacing it if the goroutines with that
+// name finished without becoming ready and restart is true. The lock must be
+// held.
+func (r *readiness) get(name string, restart bool) *readyName {
+	n, ok := r.names[name]
+	if !ok || (restart && n.err != nil) {
+		n = &readyName{
+			done: make(chan struct{}),
+		}
+		r.names[name] = n
+	}
+
+	return n
+}
+
+// start adds a goroutine named name
+func (r *readiness) start(name string) *readyName {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n := r.get(name, true)
+	n.running++
+
+	return n
+}
+
+// ready marks the goroutines with the name as ready, or as failed if err is
+// not nil
+func (r *readiness) ready(n *readyName, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !n.resolved() {
+		n.err = err
+		close(n.done)
+	}
+}
+
+// finish removes a goroutine with the name
+func (r *readiness) finish(n *readyName, name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n.running--
+	if n.running == 0 && !n.resolved() {
+		n.err = fmt.Errorf("%v: %w", name, ErrNotReady)
+		close(n.done)
+	}
+}
+
+// wait returns the readiness of name
+func (r *readiness) wait(name string) *readyName {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.get(name, false)
+}
+
+// goroutineReadiness is the readiness of a single goroutine
+type goroutineReadiness struct {
+	readiness *readiness
+	name      *readyName // Nil for goroutines without a name
+
+	once  sync.Once // Done once Ready() is called or the startup timeout expired
+	timer Timer     // Startup timeout set with WithStartupTimeout
+}
+
+// startReadiness starts tracking the readiness of a goroutine
+func (m *GoroutineManager) startReadiness(g *goroutine) {
+	g.readiness = &goroutineReadiness{
+		readiness: m.readiness,
+	}
+
+	if g.options.name != "" {
+		g.readiness.name = m.readiness.start(g.options.name)
+	}
+
+	if timeout := g.options.startupTimeout; timeout > 0 {
+		g.readiness.timer = m.options.clock.AfterFunc(timeout, func() {
+			g.readiness.once.Do(func() {
+				m.startupTimedOut(g, timeout)
+			})
+		})
+	}
+}
+
+// startupTimedOut collects the error of a goroutine that didn't become ready
+// within its startup timeout
+func (m *GoroutineManager) startupTimedOut(g *goroutine, timeout time.Duration) {
+	err := fmt.Errorf("%w within %v", ErrStartupTimeout, timeout)
+	if g.options.name != "" {
+		err = fmt.Errorf("%v: %w", g.options.name, err)
+	}
+
+	m.options.logger.Error("goroutine didn't become ready in time", "goroutine", g.options.name, "id", g.managedID, "timeout", timeout)
+
+	m.collectError(err)
+
+	if g.readiness.name != nil {
+		m.readiness.ready(g.readiness.name, err)
+	}
+
+	if g.options.stopOnStartupTimeout {
+		m.StopAllGoroutines()
+	}
+}
+
+// finishReadiness stops tracking the readiness of a goroutine
+func (m *GoroutineManager) finishReadiness(g *goroutine) {
+	if g.readiness.timer != nil {
+		g.readiness.timer.Stop()
+	}
+
+	if g.readiness.name != nil {
+		m.readiness.finish(g.readiness.name, g.options.name)
+	}
+}
+
+// Ready signals that the goroutine whose context ctx is derived from has
+// initialized, e.g. once a server is listening, which releases the callers of
+// WaitReady() waiting for its name. Calling it multiple times is safe. It does
+// nothing for other contexts, or if the goroutine's startup timeout expired.
+func Ready(ctx context.Context) {
+	r, ok := ctx.Value(readyKey{}).(*goroutineReadiness)
+	if !ok {
+		return
+	}
+
+	r.once.Do(func() {
+		if r.name != nil {
+			r.readiness.ready(r.name, nil)
+		}
+	})
+}
+
+// WaitReady waits until a goroutine with each of the names set with
+// WithGoroutineName has called Ready(), e.g. to start workers and wait until
+// they are serving. Goroutines that weren't started yet are waited for too.
+// It returns an error wrapping ErrNotReady if all goroutines with a name
+// finished without calling Ready(), an error wrapping ErrStartupTimeout if a
+// goroutine with a name didn't call it within its WithStartupTimeout, or ctx's
+// error if ctx is done first. The readiness of a name is kept once its
+// goroutines finished, so the names must come from a bounded set.
+//
+// Usage:
+//
+//	m.StartForegroundGoroutine(func(ctx context.Context) {
+//		listener := listen()
+//		manager.Ready(ctx)
+//
+//		serve(ctx, listener)
+//	}, manager.WithGoroutineName("server"))
+//
+//	if err := m.WaitReady(ctx, "server"); err != nil {
+//		return err
+//	}
+func (m *GoroutineManager) WaitReady(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		n := m.readiness.wait(name)
+
+		select {
+		case <-n.done:
+			if n.err != nil {
+				return n.err
+			}
+
+		case <-ctx.Done():
+			// Goroutines that became ready before ctx was done count as ready
+			select {
+			case <-n.done:
+				if n.err != nil {
+					return n.err
+				}
+
+			default:
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
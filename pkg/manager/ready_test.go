@@ -0,0 +1,150 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitReady(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify goroutines that aren't started yet are waited for too.
+	waited := make(chan error, 1)
+	go func() {
+		waited <- m.WaitReady(context.Background(), "server", "worker")
+	}()
+
+	release := make(chan struct{})
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		Ready(ctx)
+		Ready(ctx)
+
+		<-ctx.Done()
+	}, WithGoroutineName("server"))
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-release
+		Ready(ctx)
+
+		<-ctx.Done()
+	}, WithGoroutineName("worker"))
+
+	select {
+	case err := <-waited:
+		require.Failf(t, "unexpected WaitReady return", "%v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-waited)
+
+	// Verify readiness is kept once signalled.
+	require.NoError(t, m.WaitReady(context.Background(), "server", "worker"))
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.NoError(t, m.WaitReady(context.Background(), "server"))
+	require.NoError(t, errs)
+}
+
+func TestWaitReadyNotReady(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify goroutines finishing without becoming ready fail the wait.
+	m.StartForegroundGoroutine(func(_ context.Context) {}, WithGoroutineName("server"))
+	m.Wait()
+
+	err := m.WaitReady(context.Background(), "server")
+	require.ErrorIs(t, err, ErrNotReady)
+	require.ErrorContains(t, err, "server")
+
+	// Verify restarted goroutines can still become ready.
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		Ready(ctx)
+	}, WithGoroutineName("server"))
+	m.Wait()
+
+	require.NoError(t, m.WaitReady(context.Background(), "server"))
+	require.NoError(t, errs)
+}
+
+func TestWaitReadyContext(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Verify the wait is aborted once ctx is done.
+	require.ErrorIs(t, m.WaitReady(ctx, "missing"), context.Canceled)
+
+	// Verify contexts not derived from a goroutine context are ignored.
+	Ready(context.Background())
+
+	require.NoError(t, errs)
+}
+
+func TestWithStartupTimeout(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		Ready(ctx)
+
+		<-ctx.Done()
+	}, WithGoroutineName("fast"), WithStartupTimeout(time.Hour))
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+
+		// Verify becoming ready after the timeout doesn't release waiters.
+		Ready(ctx)
+	}, WithGoroutineName("slow"), WithStartupTimeout(10*time.Millisecond))
+
+	// Verify goroutines that don't become ready in time fail the wait.
+	require.NoError(t, m.WaitReady(context.Background(), "fast"))
+
+	err := m.WaitReady(context.Background(), "slow")
+	require.ErrorIs(t, err, ErrStartupTimeout)
+	require.EqualError(t, err, "slow: goroutine didn't become ready within 10ms")
+
+	// Verify the goroutine keeps running.
+	require.Equal(t, StateRunning, m.State())
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.ErrorIs(t, errs, ErrStartupTimeout)
+	require.ErrorIs(t, m.WaitReady(context.Background(), "slow"), ErrStartupTimeout)
+}
+
+func TestWithStopOnStartupTimeout(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	}, WithStartupTimeout(10*time.Millisecond), WithStopOnStartupTimeout())
+
+	// Verify all goroutines are stopped once the startup timeout expires.
+	m.Wait()
+	<-m.Context().Done()
+
+	require.ErrorIs(t, errs, ErrStartupTimeout)
+}
@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// newAtomicPointer returns an atomic pointer to a copy of v, for settings that
+// can be replaced on a live goroutine manager
+func newAtomicPointer[T any](v T) *atomic.Pointer[T] {
+	p := &atomic.Pointer[T]{}
+	p.Store(&v)
+
+	return p
+}
+
+// slowWatchdog holds the settings of WithSlowThreshold
+type slowWatchdog struct {
+	threshold time.Duration
+	hook      func(info GoroutineInfo, stack []byte)
+}
+
+// SetHooks replaces the lifecycle hooks of a live goroutine manager, e.g. to
+// enable reporting from an admin endpoint. Hooks that are already running
+// finish with the previous hooks.
+func (m *GoroutineManager) SetHooks(hooks GoroutineManagerHooks) {
+	m.hooks.Store(&hooks)
+}
+
+// Hooks returns the current lifecycle hooks, e.g. to change one of them with
+// SetHooks()
+func (m *GoroutineManager) Hooks() GoroutineManagerHooks {
+	return *m.hooks.Load()
+}
+
+// SetSlowThreshold changes the threshold and hook of WithSlowThreshold on a
+// live goroutine manager. A threshold of zero disables the detection of slow
+// goroutines. Goroutines that are already running keep the previous settings.
+func (m *GoroutineManager) SetSlowThreshold(threshold time.Duration, hook func(info GoroutineInfo, stack []byte)) {
+	m.slowWatchdog.Store(&slowWatchdog{threshold, hook})
+}
+
+// SetGoroutineHighWatermark changes the watermark and hook of
+// WithGoroutineHighWatermark on a live goroutine manager. A watermark of zero
+// disables it. If the number of running goroutines crosses the new watermark,
+// hook is called right away.
+func (m *GoroutineManager) SetGoroutineHighWatermark(n int, hook func(running int, exceeded bool)) {
+	m.crossHighWatermark(m.stats.setHighWatermark(n, hook))
+}
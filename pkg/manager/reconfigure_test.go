@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetHooks(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var panics atomic.Int32
+	hooks := m.Hooks()
+	hooks.OnPanic = func(GoroutineInfo, *PanicError) {
+		panics.Add(1)
+	}
+	m.SetHooks(hooks)
+
+	// Verify the new hook is called for later panics.
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	require.Equal(t, int32(1), panics.Load())
+	require.NotNil(t, m.Hooks().OnPanic)
+}
+
+func TestSetTagLimit(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithTagLimit("upload", 1))
+
+	var running atomic.Int32
+	release := make(chan struct{})
+	for range 3 {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			running.Add(1)
+
+			<-release
+		}, WithTags("upload"))
+	}
+
+	require.Eventually(t, func() bool {
+		return running.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	// Verify raising the limit starts waiting goroutines.
+	m.SetTagLimit("upload", 2)
+	require.Eventually(t, func() bool {
+		return running.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	// Verify removing the limit starts all of them.
+	m.SetTagLimit("upload", 0)
+	require.Eventually(t, func() bool {
+		return running.Load() == 3
+	}, time.Second, time.Millisecond)
+
+	// Verify new limits apply to goroutines started later.
+	m.SetTagLimit("download", 1)
+	for range 2 {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			running.Add(1)
+
+			<-release
+		}, WithTags("download"))
+	}
+
+	require.Eventually(t, func() bool {
+		return running.Load() == 4
+	}, time.Second, time.Millisecond)
+	require.Never(t, func() bool {
+		return running.Load() > 4
+	}, 20*time.Millisecond, time.Millisecond)
+
+	close(release)
+	m.Wait()
+
+	require.NoError(t, errs)
+}
+
+func TestSetSlowThreshold(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	slow := make(chan GoroutineInfo, 1)
+	m.SetSlowThreshold(time.Millisecond, func(info GoroutineInfo, _ []byte) {
+		slow <- info
+	})
+
+	// Verify goroutines started afterwards are watched.
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	}, WithGoroutineName("slow"))
+
+	require.Equal(t, "slow", (<-slow).Name)
+
+	m.StopAllGoroutines()
+	m.Wait()
+}
+
+func TestSetGoroutineHighWatermark(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	release := make(chan struct{})
+	for range 3 {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			<-release
+		})
+	}
+
+	// Verify lowering the watermark below the running goroutines fires the
+	// hook right away, and disabling it counts as recovering.
+	var crossings []bool
+	hook := func(running int, exceeded bool) {
+		require.Equal(t, 3, running)
+
+		crossings = append(crossings, exceeded)
+	}
+
+	m.SetGoroutineHighWatermark(2, hook)
+	require.Equal(t, []bool{true}, crossings)
+
+	m.SetGoroutineHighWatermark(0, hook)
+	require.Equal(t, []bool{true, false}, crossings)
+
+	close(release)
+	m.Wait()
+}
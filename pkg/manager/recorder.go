@@ -0,0 +1,115 @@
+package manager
+
+const (
+	MetricGoroutinesStarted  = "goroutines_started_total"   // Counter of started goroutines, labeled by LabelKind
+	MetricGoroutinesRunning  = "goroutines_running"         // Gauge of goroutines that haven't finished yet, labeled by LabelKind
+	MetricGoroutinesFinished = "goroutines_finished_total"  // Counter of finished goroutines, labeled by LabelKind
+	MetricGoroutineDuration  = "goroutine_duration_seconds" // Histogram of the durations of finished goroutines in seconds, labeled by LabelGoroutine
+	MetricPanics             = "panics_total"               // Counter of panics recovered from goroutines, labeled by LabelGoroutine
+
+	LabelManager   = "manager"   // Label containing the name of the goroutine manager, added to all metrics if set with WithName
+	LabelKind      = "kind"      // Label containing the kind of goroutine, either "foreground" or "background"
+	LabelGoroutine = "goroutine" // Label containing the name of the goroutine or its worker group, or "" if it has none
+)
+
+// StatsRecorder receives the metrics of a goroutine manager as lifecycle
+// events happen, so that any metrics backend can be plugged in. The names of
+// the metrics are the Metric* constants and their labels the Label*
+// constants. Calls are serialized, so they should not block for long.
+type StatsRecorder interface {
+	AddCounter(name string, delta float64, labels map[string]string)       // Adds delta to a counter
+	SetGauge(name string, value float64, labels map[string]string)         // Sets a gauge to value
+	ObserveHistogram(name string, value float64, labels map[string]string) // Adds value to a histogram
+}
+
+// nopStatsRecorder is the default stats recorder, which discards all metrics
+type nopStatsRecorder struct{}
+
+func (nopStatsRecorder) AddCounter(string, float64, map[string]string)       {}
+func (nopStatsRecorder) SetGauge(string, float64, map[string]string)         {}
+func (nopStatsRecorder) ObserveHistogram(string, float64, map[string]string) {}
+
+// labeledStatsRecorder adds labels to all metrics, e.g. LabelManager
+type labeledStatsRecorder struct {
+	recorder StatsRecorder
+	labels   map[string]string
+}
+
+// with returns a copy of labels with the labels of the recorder
+func (r labeledStatsRecorder) with(labels map[string]string) map[string]string {
+	out := make(map[string]string, len(labels)+len(r.labels))
+	for key, value := range labels {
+		out[key] = value
+	}
+	for key, value := range r.labels {
+		out[key] = value
+	}
+
+	return out
+}
+
+func (r labeledStatsRecorder) AddCounter(name string, value float64, labels map[string]string) {
+	r.recorder.AddCounter(name, value, r.with(labels))
+}
+
+func (r labeledStatsRecorder) SetGauge(name string, value float64, labels map[string]string) {
+	r.recorder.SetGauge(name, value, r.with(labels))
+}
+
+func (r labeledStatsRecorder) ObserveHistogram(name string, value float64, labels map[string]string) {
+	r.recorder.ObserveHistogram(name, value, r.with(labels))
+}
+
+// goroutineStatsRecorder is the stats recorder of a goroutine, see
+// WithGoroutineTelemetry. Its calls are serialized with the calls of the
+// goroutine manager.
+type goroutineStatsRecorder struct {
+	stats  *stats
+	labels map[string]string
+}
+
+// recorder returns the labeled recorder. The lock of the stats must be held.
+func (r goroutineStatsRecorder) recorder() StatsRecorder {
+	return labeledStatsRecorder{r.stats.recorder, r.labels}
+}
+
+func (r goroutineStatsRecorder) AddCounter(name string, value float64, labels map[string]string) {
+	r.stats.lock.Lock()
+	defer r.stats.lock.Unlock()
+
+	r.recorder().AddCounter(name, value, labels)
+}
+
+func (r goroutineStatsRecorder) SetGauge(name string, value float64, labels map[string]string) {
+	r.stats.lock.Lock()
+	defer r.stats.lock.Unlock()
+
+	r.recorder().SetGauge(name, value, labels)
+}
+
+func (r goroutineStatsRecorder) ObserveHistogram(name string, value float64, labels map[string]string) {
+	r.stats.lock.Lock()
+	defer r.stats.lock.Unlock()
+
+	r.recorder().ObserveHistogram(name, value, labels)
+}
+
+// metricsName returns the value of LabelGoroutine for a goroutine, which is
+// the name of its worker group for workers started with StartWorkers, so that
+// workers don't add a series each
+func (g *goroutine) metricsName() string {
+	if g.options.group != "" {
+		return g.options.group
+	}
+
+	return g.options.name
+}
+
+// kind returns the value of LabelKind for a goroutine
+func (g *goroutine) kind() string {
+	if g.foreground {
+		return "foreground"
+	}
+
+	return "background"
+}
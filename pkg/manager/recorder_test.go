@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRecorder struct {
+	lock sync.Mutex
+
+	counters   map[string]float64
+	gauges     map[string]float64
+	histograms map[string][]float64
+}
+
+func newTestRecorder() *testRecorder {
+	return &testRecorder{
+		counters:   map[string]float64{},
+		gauges:     map[string]float64{},
+		histograms: map[string][]float64{},
+	}
+}
+
+func recorderKey(name string, labels map[string]string) string {
+	return name + "{" + labels[LabelManager] + labels[LabelKind] + labels[LabelGoroutine] + "}"
+}
+
+func (r *testRecorder) AddCounter(name string, delta float64, labels map[string]string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.counters[recorderKey(name, labels)] += delta
+}
+
+func (r *testRecorder) SetGauge(name string, value float64, labels map[string]string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.gauges[recorderKey(name, labels)] = value
+}
+
+func (r *testRecorder) ObserveHistogram(name string, value float64, labels map[string]string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.histograms[recorderKey(name, labels)] = append(r.histograms[recorderKey(name, labels)], value)
+}
+
+func TestWithStatsRecorder(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRecorder()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithStatsRecorder(r))
+
+	release := make(chan struct{})
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		<-release
+	}, WithGoroutineName("worker"))
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("panicker"), WithPanicPolicy(PanicPolicyRecord))
+
+	require.Eventually(t, func() bool {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		return r.counters[MetricPanics+"{panicker}"] == 1
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	m.Wait()
+
+	// Verify the lifecycle events are recorded.
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	require.Equal(t, 2.0, r.counters[MetricGoroutinesStarted+"{foreground}"])
+	require.Equal(t, 2.0, r.counters[MetricGoroutinesFinished+"{foreground}"])
+	require.Equal(t, 0.0, r.gauges[MetricGoroutinesRunning+"{foreground}"])
+	require.Len(t, r.histograms[MetricGoroutineDuration+"{worker}"], 1)
+	require.Len(t, r.histograms[MetricGoroutineDuration+"{panicker}"], 1)
+}
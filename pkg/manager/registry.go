@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrAlreadyRegistered = errors.New("goroutine manager already registered") // Returned by Register() if a goroutine manager with the same name is registered
+)
+
+var (
+	registryLock sync.Mutex
+	registry     = map[string]*GoroutineManager{}
+)
+
+// Register adds m to the process-wide registry of goroutine managers under
+// name, so that it can be looked up with Lookup() and is included in
+// RegistryHandler() and aggregate metrics. Registering is optional.
+func Register(name string, m *GoroutineManager) error {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("%w: %v", ErrAlreadyRegistered, name)
+	}
+
+	registry[name] = m
+
+	return nil
+}
+
+// Unregister removes the goroutine manager registered under name
+func Unregister(name string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	delete(registry, name)
+}
+
+// Lookup returns the goroutine manager registered under name
+func Lookup(name string) (*GoroutineManager, bool) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	m, ok := registry[name]
+
+	return m, ok
+}
+
+// Registered returns the names of all registered goroutine managers, sorted
+// alphabetically
+func Registered() []string {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// registeredHealth is the summary of a registered goroutine manager served by
+// RegistryHandler()
+type registeredHealth struct {
+	Name         string              `json:"name,omitempty"`
+	State        string              `json:"state"`
+	Healthy      bool                `json:"healthy"`
+	Foreground   GoroutineCounts     `json:"foreground"`
+	Background   GoroutineCounts     `json:"background"`
+	RecentPanics int                 `json:"recentPanics"`
+	Supervised   []SupervisionStatus `json:"supervised,omitempty"`
+}
+
+// RegistryHandler returns a debug handler that serves a JSON object with a
+// health summary of each registered goroutine manager, keyed by name
+func RegistryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		out := map[string]registeredHealth{}
+		for _, name := range Registered() {
+			m, ok := Lookup(name)
+			if !ok {
+				continue
+			}
+
+			report := m.Health()
+			out[name] = registeredHealth{
+				Name:         m.Name(),
+				State:        report.State.String(),
+				Healthy:      report.Healthy,
+				Foreground:   report.Foreground,
+				Background:   report.Background,
+				RecentPanics: len(report.RecentPanics),
+				Supervised:   report.Supervised,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
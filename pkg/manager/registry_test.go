@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	// Not parallel since the registry is package-level state.
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.NoError(t, Register("scheduler", m))
+	defer Unregister("scheduler")
+
+	// Verify names can only be registered once.
+	require.ErrorIs(t, Register("scheduler", m), ErrAlreadyRegistered)
+
+	actual, ok := Lookup("scheduler")
+	require.True(t, ok)
+	require.Equal(t, m, actual)
+	require.Contains(t, Registered(), "scheduler")
+
+	_, ok = Lookup("missing")
+	require.False(t, ok)
+
+	// Verify the debug endpoint includes the manager.
+	rec := httptest.NewRecorder()
+	RegistryHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var out map[string]registeredHealth
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, "idle", out["scheduler"].State)
+	require.True(t, out["scheduler"].Healthy)
+
+	Unregister("scheduler")
+	_, ok = Lookup("scheduler")
+	require.False(t, ok)
+}
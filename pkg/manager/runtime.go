@@ -0,0 +1,75 @@
+package manager
+
+import (
+	"context"
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	runtimeMetricGoroutines       = "/sched/goroutines:goroutines"
+	runtimeMetricGCPauses         = "/sched/pauses/total/gc:seconds"
+	runtimeMetricSchedulerLatency = "/sched/latencies:seconds"
+	runtimeMetricHeapBytes        = "/memory/classes/heap/objects:bytes"
+)
+
+// RuntimeSample contains the runtime metrics sampled by StartRuntimeMonitor()
+type RuntimeSample struct {
+	Time             time.Time                 // Time at which the sample was taken
+	Goroutines       uint64                    // Number of goroutines in the process, including ones that aren't managed
+	HeapBytes        uint64                    // Memory occupied by live and not yet swept heap objects
+	GCPauses         *metrics.Float64Histogram // Distribution of stop-the-world pauses caused by the GC since the process started, in seconds
+	SchedulerLatency *metrics.Float64Histogram // Distribution of the time goroutines spent runnable before running since the process started, in seconds
+}
+
+// Starts a background goroutine that samples runtime metrics every interval,
+// beginning immediately. The latest sample is available as Stats().Runtime and
+// each sample is passed to the OnRuntimeSample hook. Metrics that aren't
+// supported by the runtime are left empty.
+func (m *GoroutineManager) StartRuntimeMonitor(interval time.Duration, opts ...StartOption) {
+	samples := []metrics.Sample{
+		{Name: runtimeMetricGoroutines},
+		{Name: runtimeMetricHeapBytes},
+		{Name: runtimeMetricGCPauses},
+		{Name: runtimeMetricSchedulerLatency},
+	}
+
+	m.StartPeriodicGoroutine(interval, func(_ context.Context) {
+		metrics.Read(samples)
+
+		sample := RuntimeSample{
+			Time: m.options.clock.Now(),
+		}
+		for _, s := range samples {
+			switch {
+			case s.Name == runtimeMetricGoroutines && s.Value.Kind() == metrics.KindUint64:
+				sample.Goroutines = s.Value.Uint64()
+
+			case s.Name == runtimeMetricHeapBytes && s.Value.Kind() == metrics.KindUint64:
+				sample.HeapBytes = s.Value.Uint64()
+
+			case s.Name == runtimeMetricGCPauses && s.Value.Kind() == metrics.KindFloat64Histogram:
+				sample.GCPauses = cloneFloat64Histogram(s.Value.Float64Histogram())
+
+			case s.Name == runtimeMetricSchedulerLatency && s.Value.Kind() == metrics.KindFloat64Histogram:
+				sample.SchedulerLatency = cloneFloat64Histogram(s.Value.Float64Histogram())
+			}
+		}
+
+		m.stats.setRuntime(sample)
+
+		if hook := m.hooks.Load().OnRuntimeSample; hook != nil {
+			m.callHook("OnRuntimeSample", func() {
+				hook(sample)
+			})
+		}
+	}, append([]StartOption{WithGoroutineName("runtime monitor"), WithImmediateStart(), WithBackground()}, opts...)...)
+}
+
+// cloneFloat64Histogram copies h, since metrics.Read() reuses its memory
+func cloneFloat64Histogram(h *metrics.Float64Histogram) *metrics.Float64Histogram {
+	return &metrics.Float64Histogram{
+		Counts:  append([]uint64(nil), h.Counts...),
+		Buckets: append([]float64(nil), h.Buckets...),
+	}
+}
@@ -0,0 +1,43 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartRuntimeMonitor(t *testing.T) {
+	t.Parallel()
+
+	samples := make(chan RuntimeSample, 1)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnRuntimeSample: func(sample RuntimeSample) {
+			select {
+			case samples <- sample:
+			default:
+			}
+		},
+	})
+
+	m.StartRuntimeMonitor(time.Hour)
+
+	// Verify a sample is taken immediately and exposed through the stats.
+	sample := <-samples
+	require.NotZero(t, sample.Goroutines)
+	require.NotZero(t, sample.HeapBytes)
+	require.NotNil(t, sample.GCPauses)
+	require.NotNil(t, sample.SchedulerLatency)
+
+	require.Eventually(t, func() bool {
+		return m.Stats().Runtime != nil
+	}, time.Second, time.Millisecond)
+
+	// Verify the monitor runs in the background.
+	requireNotBlocked(t, m)
+	m.StopAllGoroutines()
+	require.NoError(t, errs)
+}
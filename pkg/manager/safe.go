@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"context"
+	"errors"
+)
+
+// SafeGo starts a one-off goroutine that calls fn with a context derived from
+// ctx and captures its panics without requiring a goroutine manager. The
+// returned channel receives the error recovered from fn's panic, or nil if fn
+// returned normally, and is closed afterwards.
+//
+// It uses a goroutine manager internally, so panics are converted to errors
+// the same way: the channel receives the *PanicError with the stack trace
+// itself, so that it can be type-asserted.
+func SafeGo(ctx context.Context, fn func(context.Context), opts ...StartOption) <-chan error {
+	out := make(chan error, 1)
+
+	var errs error
+	m := NewGoroutineManager(ctx, &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(fn, opts...)
+
+	go func() {
+		defer close(out)
+
+		m.Wait()
+
+		// Release the goroutine context, which would otherwise stay
+		// registered with ctx until it is cancelled
+		m.StopAllGoroutines()
+		m.Wait()
+
+		// Send a single collected error, e.g. fn's *PanicError, itself, and join
+		// them only if there are several
+		switch collected := m.AllErrors(); len(collected) {
+		case 0:
+			out <- nil
+
+		case 1:
+			out <- collected[0]
+
+		default:
+			out <- errors.Join(collected...)
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,43 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeGo(t *testing.T) {
+	t.Parallel()
+
+	// Verify nil is sent if fn returns normally.
+	errs := SafeGo(context.Background(), func(_ context.Context) {})
+	require.NoError(t, <-errs)
+
+	_, ok := <-errs
+	require.False(t, ok)
+
+	// Verify panics are sent as panic errors.
+	err := <-SafeGo(context.Background(), func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("one-off"))
+	require.ErrorIs(t, err, testErr)
+
+	p, ok := err.(*PanicError)
+	require.True(t, ok)
+	require.Equal(t, "one-off", p.Name)
+}
+
+func TestSafeGoContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := SafeGo(ctx, func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	// Verify fn's context is derived from ctx.
+	cancel()
+	require.NoError(t, <-errs)
+}
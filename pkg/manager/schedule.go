@@ -0,0 +1,122 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ScheduledGoroutine is a handle to a goroutine that starts at a scheduled
+// time, which can be cancelled or rescheduled until it starts
+type ScheduledGoroutine struct {
+	m    *GoroutineManager
+	fn   func(context.Context)
+	opts []StartOption
+
+	lock       sync.Mutex
+	at         time.Time
+	timer      Timer
+	generation uint64 // Incremented when rescheduled, so that a stale timer doesn't start the goroutine
+	done       bool   // Set once the goroutine started or was cancelled
+	stopCancel func() bool
+}
+
+// Schedules a goroutine that starts at time at, or right away if at is in the
+// past. It is started like a goroutine started with Go(), so it can be waited
+// for to finish once it has started unless WithBackground is set. A goroutine
+// that hasn't started yet doesn't block Wait() and is cancelled once the
+// goroutine context is cancelled.
+func (m *GoroutineManager) ScheduleGoroutine(at time.Time, fn func(context.Context), opts ...StartOption) *ScheduledGoroutine {
+	s := &ScheduledGoroutine{
+		m:    m,
+		fn:   fn,
+		opts: opts,
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.schedule(at)
+	s.stopCancel = context.AfterFunc(m.internalCtx, func() {
+		s.Cancel()
+	})
+
+	return s
+}
+
+// AfterFunc schedules fn to run in a goroutine after d, like time.AfterFunc,
+// but with the goroutine context and the panic handling of the goroutine
+// manager. It is a shorthand for ScheduleGoroutine() with the time d from now
+// according to the manager's clock; the returned handle's Cancel() stops the
+// timer. The timer is cancelled once the goroutine context is cancelled.
+func (m *GoroutineManager) AfterFunc(d time.Duration, fn func(context.Context), opts ...StartOption) *ScheduledGoroutine {
+	return m.ScheduleGoroutine(m.options.clock.Now().Add(d), fn, opts...)
+}
+
+// schedule starts the timer for time at. It must be called with the lock held.
+func (s *ScheduledGoroutine) schedule(at time.Time) {
+	s.at = at
+	s.generation++
+
+	generation := s.generation
+	s.timer = s.m.options.clock.AfterFunc(at.Sub(s.m.options.clock.Now()), func() {
+		s.start(generation)
+	})
+}
+
+// start starts the goroutine unless it was rescheduled or cancelled since the
+// timer for generation was started
+func (s *ScheduledGoroutine) start(generation uint64) {
+	s.lock.Lock()
+	if s.done || s.generation != generation {
+		s.lock.Unlock()
+
+		return
+	}
+	s.done = true
+	s.lock.Unlock()
+
+	s.stopCancel()
+
+	s.m.Go(s.fn, s.opts...)
+}
+
+// Time returns the time at which the goroutine is scheduled to start
+func (s *ScheduledGoroutine) Time() time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.at
+}
+
+// Cancel prevents the goroutine from starting. It returns false if the
+// goroutine has already started or was cancelled before.
+func (s *ScheduledGoroutine) Cancel() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.done {
+		return false
+	}
+
+	s.done = true
+	s.timer.Stop()
+
+	return true
+}
+
+// Reschedule changes the time at which the goroutine starts to at. It returns
+// false if the goroutine has already started or was cancelled.
+func (s *ScheduledGoroutine) Reschedule(at time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.done {
+		return false
+	}
+
+	s.timer.Stop()
+	s.schedule(at)
+
+	return true
+}
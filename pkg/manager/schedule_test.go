@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleGoroutine(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	started := make(chan time.Time, 1)
+	s := m.ScheduleGoroutine(time.Now().Add(time.Hour), func(_ context.Context) {
+		started <- time.Now()
+	})
+
+	// Verify a pending goroutine doesn't block Wait.
+	requireNotBlocked(t, m)
+
+	// Verify the goroutine starts at the new time once rescheduled.
+	at := time.Now().Add(10 * time.Millisecond)
+	require.True(t, s.Reschedule(at))
+	require.Equal(t, at, s.Time())
+
+	require.False(t, (<-started).Before(at))
+
+	require.False(t, s.Cancel())
+	require.False(t, s.Reschedule(time.Now()))
+	require.NoError(t, errs)
+}
+
+func TestScheduleGoroutineCancel(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	started := make(chan struct{}, 2)
+	s := m.ScheduleGoroutine(time.Now().Add(10*time.Millisecond), func(_ context.Context) {
+		started <- struct{}{}
+	})
+	require.True(t, s.Cancel())
+	require.False(t, s.Cancel())
+
+	stopped := m.ScheduleGoroutine(time.Now().Add(10*time.Millisecond), func(_ context.Context) {
+		started <- struct{}{}
+	})
+
+	// Verify pending goroutines are cancelled once the manager is stopped.
+	m.StopAllGoroutines()
+
+	time.Sleep(20 * time.Millisecond)
+	require.Empty(t, started)
+	require.False(t, stopped.Cancel())
+}
+
+func TestAfterFunc(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	ran := make(chan struct{})
+	m.AfterFunc(time.Millisecond, func(ctx context.Context) {
+		defer close(ran)
+
+		// Verify the callback runs with the goroutine context.
+		actual, ok := FromContext(ctx)
+		require.True(t, ok)
+		require.Same(t, m, actual)
+
+		panic(testErr)
+	}, WithGoroutineName("callback"))
+	<-ran
+
+	m.Wait()
+
+	// Verify the callback's panic is collected instead of crashing the process.
+	var p *PanicError
+	require.ErrorAs(t, errs, &p)
+	require.Equal(t, "callback", p.Name)
+}
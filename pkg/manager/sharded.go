@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"context"
+	"hash/maphash"
+	"strconv"
+)
+
+// ShardedQueue is a set of queues with one worker each, the shards. Tasks
+// with the same key always run on the same shard in the order they were
+// enqueued, e.g. to process the events of each user in order, while tasks with
+// different keys can run in parallel.
+type ShardedQueue struct {
+	seed   maphash.Seed
+	shards []*Queue
+}
+
+// NewShardedQueue creates a sharded queue with the given number of shards,
+// each holding up to capacity tasks, and starts their workers. The shards are
+// named after the queue and their index, e.g. "events/0". Options that would
+// break the ordering of tasks, i.e. WithAdaptiveConcurrency and
+// WithWorkStealing, and WithJournal, which can't be shared by the shards, are
+// ignored. Shards and capacity below 1 are treated as 1.
+func (m *GoroutineManager) NewShardedQueue(name string, shards, capacity int, opts ...QueueOption) *ShardedQueue {
+	s := &ShardedQueue{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*Queue, max(shards, 1)),
+	}
+
+	for i := range s.shards {
+		shardName := name + "/" + strconv.Itoa(i)
+
+		options := queueOptions{
+			startOptions: []StartOption{WithGoroutineName(shardName)},
+		}
+		for _, opt := range opts {
+			opt(&options)
+		}
+
+		options.adaptive = nil
+		options.stealInterval = 0
+		options.ordered = true
+		options.journal = nil
+
+		s.shards[i] = m.newQueue(shardName, 1, capacity, options)
+	}
+
+	return s
+}
+
+// Shard returns the shard that runs the tasks with key
+func (s *ShardedQueue) Shard(key string) *Queue {
+	return s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+}
+
+// Enqueue adds a task with key to its shard, blocking while the shard is full.
+// See Queue.Enqueue().
+func (s *ShardedQueue) Enqueue(ctx context.Context, key string, fn func(context.Context)) error {
+	return s.Shard(key).Enqueue(ctx, fn)
+}
+
+// TryEnqueue adds a task with key to its shard without blocking. See
+// Queue.TryEnqueue().
+func (s *ShardedQueue) TryEnqueue(key string, fn func(context.Context)) error {
+	return s.Shard(key).TryEnqueue(fn)
+}
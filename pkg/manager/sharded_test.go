@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedQueue(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewShardedQueue("events", 4, 100)
+
+	// Verify each key always maps to the same shard.
+	require.Same(t, q.Shard("user"), q.Shard("user"))
+	require.Contains(t, m.Stats().Queues, "events/3")
+
+	var (
+		lock sync.Mutex
+		seen = map[string][]int{}
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 20; i++ {
+		for _, key := range []string{"a", "b", "c"} {
+			wg.Add(1)
+
+			require.NoError(t, q.Enqueue(context.Background(), key, func(_ context.Context) {
+				defer wg.Done()
+
+				lock.Lock()
+				defer lock.Unlock()
+
+				seen[key] = append(seen[key], i)
+			}))
+		}
+	}
+	wg.Wait()
+
+	// Verify the tasks of each key ran in the order they were enqueued.
+	expected := make([]int, 20)
+	for i := range expected {
+		expected[i] = i
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		require.Equal(t, expected, seen[key], "key "+strconv.Quote(key))
+	}
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
@@ -0,0 +1,230 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	DefaultCleanupTimeout = 30 * time.Second // Timeout of the contexts returned by CleanupContext() unless set with WithCleanupTimeout
+)
+
+type shutdownSignalKey struct{}
+
+type shutdownDeadlineKey struct{}
+
+// cleanups holds the cleanup functions registered with AddCleanup()
+type cleanups struct {
+	lock sync.Mutex
+	fns  []func() error
+	done bool
+}
+
+// Quiesce signals goroutines to stop accepting new work without cancelling
+// the goroutine context, so that they can finish their in-flight work, e.g. to
+// drain connections. Goroutines can wait for the signal with Quiesced(). Queues
+// stop accepting new tasks, but keep running the queued ones.
+//
+// Calling Quiesce() multiple times is safe. Use Terminate() or
+// StopAllGoroutines() to cancel the goroutine context afterwards.
+func (m *GoroutineManager) Quiesce() {
+	m.quiesceOnce.Do(func() {
+		close(m.quiesced)
+	})
+}
+
+// AddCleanup registers fn to run once the goroutine context is cancelled.
+// Cleanup functions run in the reverse order they were added, after the
+// OnShutdown hook, and Wait() waits for them. Returned errors are collected
+// into errs. If the goroutine context is already cancelled and the cleanup
+// functions ran, fn is called immediately.
+func (m *GoroutineManager) AddCleanup(fn func() error) {
+	m.cleanups.lock.Lock()
+	if !m.cleanups.done {
+		m.cleanups.fns = append(m.cleanups.fns, fn)
+		m.cleanups.lock.Unlock()
+
+		return
+	}
+	m.cleanups.lock.Unlock()
+
+	if err := fn(); err != nil {
+		m.collectError(err)
+	}
+}
+
+// CleanupContext returns a context that carries the values of the goroutine
+// context, but is not cancelled with it, e.g. by StopAllGoroutines(). Instead,
+// it has its own deadline set by WithCleanupTimeout (DefaultCleanupTimeout by
+// default), so that teardown code can still run after stopping all goroutines
+// without blocking a shutdown forever.
+func (m *GoroutineManager) CleanupContext() (context.Context, context.CancelFunc) {
+	timeout := m.options.cleanupTimeout
+	if timeout <= 0 {
+		timeout = DefaultCleanupTimeout
+	}
+
+	return withDeadline(m.options.clock, context.WithoutCancel(m.internalCtx), m.options.clock.Now().Add(timeout))
+}
+
+// OnStop calls fn in its own goroutine with the cause once the goroutine
+// context is cancelled, mirroring context.AfterFunc. If the context is already
+// cancelled, fn is called immediately. Calling the returned function
+// deregisters fn if it hasn't been called yet.
+func (m *GoroutineManager) OnStop(fn func(cause error)) (cancel func()) {
+	stop := context.AfterFunc(m.internalCtx, func() {
+		fn(context.Cause(m.internalCtx))
+	})
+
+	return func() {
+		stop()
+	}
+}
+
+// shutdown runs once the goroutine context is cancelled
+func (m *GoroutineManager) shutdown() {
+	m.options.logger.Info("stopping goroutines", "cause", context.Cause(m.internalCtx))
+
+	m.trace(func() TraceEvent {
+		return TraceEvent{
+			Time:  m.options.clock.Now(),
+			Type:  TraceEventCancel,
+			Error: context.Cause(m.internalCtx).Error(),
+		}
+	})
+
+	m.updateState(func(l *lifecycle) {
+		l.stopping = true
+	})
+
+	defer close(m.shutdownDone)
+	defer m.updateState(func(l *lifecycle) {
+		l.shutDown = true
+	})
+
+	m.Quiesce()
+
+	if hook := m.hooks.Load().OnShutdown; hook != nil {
+		m.callHook("OnShutdown", func() {
+			if err := hook(); err != nil {
+				m.options.logger.Error("shutdown hook failed", "error", err)
+
+				m.collectError(err)
+			}
+		})
+	}
+
+	m.cleanups.lock.Lock()
+	fns := m.cleanups.fns
+	m.cleanups.fns = nil
+	m.cleanups.done = true
+	m.cleanups.lock.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		m.callHook("cleanup", func() {
+			if err := fns[i](); err != nil {
+				m.options.logger.Error("cleanup function failed", "error", err)
+
+				m.collectError(err)
+			}
+		})
+	}
+}
+
+// Terminate quiesces the goroutine manager and stops all goroutines by
+// cancelling the goroutine context, but doesn't wait for them to finish.
+func (m *GoroutineManager) Terminate() {
+	m.Quiesce()
+
+	m.StopAllGoroutines()
+}
+
+// Quiesced returns a channel that is closed once Quiesce() or Terminate() is
+// called or the goroutine context is cancelled
+func (m *GoroutineManager) Quiesced() <-chan struct{} {
+	return m.quiesced
+}
+
+// isQuiesced returns true if the channel returned by Quiesced() is closed
+func (m *GoroutineManager) isQuiesced() bool {
+	select {
+	case <-m.quiesced:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// ShutdownSignal returns the soft-stop signal of the goroutine manager whose
+// goroutine context ctx is derived from: a channel that is closed once the
+// goroutine manager is quiesced or stopped. Goroutines can use it to finish
+// their in-flight work on a soft stop, while still aborting if ctx is
+// cancelled (a hard stop). If ctx is not derived from a goroutine context,
+// ctx.Done() is returned.
+//
+// Usage:
+//
+//	for {
+//		select {
+//		case <-manager.ShutdownSignal(ctx):
+//			return // Stop accepting new work
+//		case req := <-requests:
+//			handle(ctx, req) // Aborts if ctx is cancelled
+//		}
+//	}
+func ShutdownSignal(ctx context.Context) <-chan struct{} {
+	if signal, ok := ctx.Value(shutdownSignalKey{}).(chan struct{}); ok {
+		return signal
+	}
+
+	return ctx.Done()
+}
+
+// ShutdownDeadline returns the deadline published by StopAllGoroutinesWithin()
+// of the goroutine manager whose goroutine context ctx is derived from. It
+// returns false if no deadline was published, or if ctx is not derived from a
+// goroutine context.
+//
+// Usage:
+//
+//	<-ctx.Done()
+//	if deadline, ok := manager.ShutdownDeadline(ctx); ok {
+//		flushCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), deadline)
+//		defer cancel()
+//
+//		flush(flushCtx)
+//	}
+func ShutdownDeadline(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Value(shutdownDeadlineKey{}).(*atomic.Pointer[time.Time])
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if d := deadline.Load(); d != nil {
+		return *d, true
+	}
+
+	return time.Time{}, false
+}
+
+// publishShutdownDeadline sets the shutdown deadline of the goroutine manager
+// and its children, unless an earlier one is set already
+func (m *GoroutineManager) publishShutdownDeadline(deadline time.Time) {
+	for _, child := range m.children.reversed() {
+		child.publishShutdownDeadline(deadline)
+	}
+
+	for {
+		current := m.shutdownDeadline.Load()
+		if current != nil && !current.After(deadline) {
+			return
+		}
+
+		if m.shutdownDeadline.CompareAndSwap(current, &deadline) {
+			return
+		}
+	}
+}
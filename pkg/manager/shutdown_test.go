@@ -0,0 +1,339 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuiesce(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Start a goroutine that accepts work until the manager is quiesced, and
+	// then finishes its in-flight work.
+	work := make(chan int)
+	var processed atomic.Int64
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		for {
+			select {
+			case <-m.Quiesced():
+				processed.Add(100)
+
+				return
+
+			case <-work:
+				processed.Add(1)
+			}
+		}
+	})
+
+	work <- 1
+	work <- 1
+
+	// Verify quiescing doesn't cancel the goroutine context, but lets
+	// goroutines finish.
+	m.Quiesce()
+	m.Quiesce()
+	m.Wait()
+	requireNotDone(t, m)
+	require.Equal(t, int64(102), processed.Load())
+
+	// Verify terminating cancels the goroutine context.
+	m.Terminate()
+	requireDone(t, m)
+	require.NoError(t, errs)
+}
+
+func TestTerminate(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	// Verify terminating quiesces the manager and stops all goroutines.
+	m.Terminate()
+	m.Wait()
+
+	select {
+	case <-m.Quiesced():
+	default:
+		t.Fatal("expected goroutine manager to be quiesced")
+	}
+	require.NoError(t, errs)
+}
+
+func TestQuiesceQueue(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	q := m.NewQueue("test", 1, 10)
+
+	started := make(chan any)
+	done := make(chan any)
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+		close(started)
+		<-done
+	}))
+	<-started
+
+	var runs atomic.Int64
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {
+		runs.Add(1)
+	}))
+
+	// Verify a quiesced queue rejects new tasks, but runs the queued ones.
+	m.Quiesce()
+	require.ErrorIs(t, q.TryEnqueue(func(_ context.Context) {}), ErrQueueStopped)
+	require.ErrorIs(t, q.Enqueue(context.Background(), func(_ context.Context) {}), ErrQueueStopped)
+
+	close(done)
+	require.Eventually(t, func() bool {
+		return runs.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	m.Terminate()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestShutdownSignal(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	softStopped := make(chan any)
+	hardStopped := make(chan any)
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		// Verify the signal is available from derived contexts.
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		<-ShutdownSignal(ctx)
+		close(softStopped)
+
+		<-ctx.Done()
+		close(hardStopped)
+	})
+
+	// Verify quiescing sends the soft-stop signal without cancelling the context.
+	m.Quiesce()
+	<-softStopped
+	require.Never(t, func() bool {
+		<-hardStopped
+		return true
+	}, 50*time.Millisecond, time.Millisecond)
+
+	// Verify terminating cancels the context.
+	m.Terminate()
+	<-hardStopped
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestShutdownSignalStop(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify stopping all goroutines also sends the soft-stop signal.
+	m.StopAllGoroutines()
+	<-ShutdownSignal(m.Context())
+	<-m.Quiesced()
+	require.NoError(t, errs)
+}
+
+func TestShutdownSignalWithoutManager(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Verify contexts not derived from a goroutine context fall back to Done().
+	signal := ShutdownSignal(ctx)
+	cancel()
+	<-signal
+}
+
+func TestStopAllGoroutinesWithin(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	child := m.NewChild("child", &errs, GoroutineManagerHooks{})
+
+	// Verify no deadline is published before stopping.
+	_, ok := ShutdownDeadline(m.Context())
+	require.False(t, ok)
+
+	deadlines := make(chan time.Time, 1)
+	child.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+
+		deadline, ok := ShutdownDeadline(ctx)
+		require.True(t, ok)
+
+		deadlines <- deadline
+	})
+
+	// Verify the deadline is published to goroutines of child managers before
+	// they are stopped.
+	before := time.Now()
+	m.StopAllGoroutinesWithin(time.Minute)
+	m.WaitTree()
+
+	deadline := <-deadlines
+	require.WithinRange(t, deadline, before.Add(time.Minute), time.Now().Add(time.Minute))
+
+	// Verify a later deadline doesn't replace an earlier one.
+	m.StopAllGoroutinesWithin(time.Hour)
+
+	again, ok := ShutdownDeadline(m.Context())
+	require.True(t, ok)
+	require.Equal(t, deadline, again)
+	require.NoError(t, errs)
+}
+
+func TestShutdownDeadlineWithoutManager(t *testing.T) {
+	t.Parallel()
+
+	_, ok := ShutdownDeadline(context.Background())
+	require.False(t, ok)
+}
+
+func TestHooks_OnShutdown(t *testing.T) {
+	t.Parallel()
+
+	errHook := errors.New("hook failed")
+
+	var calls atomic.Uint64
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{
+		OnShutdown: func() error {
+			calls.Add(1)
+
+			return errHook
+		},
+	})
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	// Verify the hook doesn't run before the goroutine context is cancelled.
+	require.Never(t, func() bool {
+		return calls.Load() > 0
+	}, 50*time.Millisecond, time.Millisecond)
+
+	// Verify the hook ran once and its error is collected.
+	m.StopAllGoroutines()
+	m.StopAllGoroutines()
+	m.Wait()
+	require.Equal(t, uint64(1), calls.Load())
+	require.ErrorIs(t, errs, errHook)
+}
+
+func TestAddCleanup(t *testing.T) {
+	t.Parallel()
+
+	errCleanup := errors.New("cleanup failed")
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var order []int
+	m.AddCleanup(func() error {
+		order = append(order, 1)
+
+		return errCleanup
+	})
+	m.AddCleanup(func() error {
+		order = append(order, 2)
+
+		return nil
+	})
+
+	// Verify the cleanup functions run in reverse order and errors are collected.
+	m.StopAllGoroutines()
+	m.Wait()
+	require.Equal(t, []int{2, 1}, order)
+	require.ErrorIs(t, errs, errCleanup)
+
+	// Verify cleanup functions added after shutdown run immediately.
+	m.AddCleanup(func() error {
+		order = append(order, 3)
+
+		return nil
+	})
+	require.Equal(t, []int{2, 1, 3}, order)
+}
+
+func TestOnStop(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	causes := make(chan error, 1)
+	m.OnStop(func(cause error) {
+		causes <- cause
+	})
+
+	var cancelledCalls atomic.Uint64
+	cancel := m.OnStop(func(_ error) {
+		cancelledCalls.Add(1)
+	})
+	cancel()
+
+	// Verify the callback receives the stop cause.
+	m.StopAllGoroutines()
+	require.ErrorIs(t, <-causes, ErrGoroutineStopped)
+
+	// Verify deregistered callbacks are not called.
+	require.Never(t, func() bool {
+		return cancelledCalls.Load() > 0
+	}, 50*time.Millisecond, time.Millisecond)
+
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestCleanupContext(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+
+	var errs error
+	m := NewGoroutineManager(context.WithValue(context.Background(), key{}, "value"), &errs, GoroutineManagerHooks{}, WithCleanupTimeout(time.Minute))
+
+	m.StopAllGoroutines()
+
+	// Verify the context keeps the values, but isn't cancelled with the goroutine context.
+	before := time.Now()
+	ctx, cancel := m.CleanupContext()
+	defer cancel()
+
+	require.Equal(t, "value", ctx.Value(key{}))
+	require.NoError(t, ctx.Err())
+
+	// Verify the context has its own deadline. Its expiry is tested with a fake
+	// clock in managertest.
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.WithinRange(t, deadline, before.Add(time.Minute), time.Now().Add(time.Minute))
+
+	m.Wait()
+	require.NoError(t, errs)
+}
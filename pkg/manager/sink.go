@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"slices"
+	"strings"
+)
+
+// ErrorSink receives the errors collected by a goroutine manager, e.g. errors
+// recovered from panics. Calls to Add are serialized and happen in the order
+// the errors were collected, so Add should not block for long.
+type ErrorSink interface {
+	Add(err error)
+}
+
+// ErrorSinkFunc is a function that implements ErrorSink
+type ErrorSinkFunc func(err error)
+
+func (f ErrorSinkFunc) Add(err error) {
+	f(err)
+}
+
+// JoinErrors returns a sink that joins all errors into errs, keeping the value
+// errs had when the sink was created. This is what NewGoroutineManager does, so
+// it lets callers that pass &errs migrate to the sink-based constructors one
+// call at a time. errs must only be accessed once no more errors are added.
+func JoinErrors(errs *error) ErrorSink {
+	j := &joinedErrors{errs: errs}
+
+	// Flatten an initial joined error so that the list stays flat
+	if joined, ok := (*errs).(interface{ Unwrap() []error }); ok {
+		j.list = append(j.list, joined.Unwrap()...)
+	} else if *errs != nil {
+		j.list = append(j.list, *errs)
+	}
+
+	return j
+}
+
+// joinedErrors joins all errors into one error variable
+type joinedErrors struct {
+	errs *error
+	list []error
+}
+
+func (j *joinedErrors) Add(err error) {
+	// errors.Join drops nil errors too
+	if err == nil {
+		return
+	}
+
+	j.list = append(j.list, err)
+
+	// Each joined error gets a snapshot of the list, so that errors read from
+	// errs don't change when more errors are added. The full slice expression
+	// makes later appends copy instead of writing into the snapshot.
+	*j.errs = &joinError{errs: j.list[:len(j.list):len(j.list)]}
+}
+
+// joinError is like the error returned by errors.Join, but it is built from a
+// snapshot of the list without copying it
+type joinError struct {
+	errs []error
+}
+
+func (e *joinError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors in the order they were collected
+func (e *joinError) Unwrap() []error {
+	return slices.Clone(e.errs)
+}
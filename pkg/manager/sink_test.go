@@ -0,0 +1,122 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGoroutineManagerWithSink(t *testing.T) {
+	t.Parallel()
+
+	errs := make(chan error, 1)
+	m := NewGoroutineManagerWithSink(context.Background(), ErrorSinkFunc(func(err error) {
+		errs <- err
+	}), GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+
+	// Verify errors are routed into the sink.
+	require.ErrorIs(t, <-errs, testErr)
+
+	m.Wait()
+	require.Len(t, m.AllErrors(), 1)
+}
+
+func TestSetErrorSink(t *testing.T) {
+	t.Parallel()
+
+	var first error
+	m := NewGoroutineManager(context.Background(), &first, GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	// Verify the errors of the previous cycle are returned on replacement.
+	var second error
+	previous := m.SetErrors(&second)
+	require.Len(t, previous, 1)
+	require.ErrorIs(t, previous[0], testErr)
+	require.Empty(t, m.AllErrors())
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic("second")
+	}, WithPanicPolicy(PanicPolicyRecord))
+	m.Wait()
+
+	// Verify new errors only go to the new target.
+	require.Len(t, PanicsFrom(first), 1)
+	require.Len(t, PanicsFrom(second), 1)
+	require.Equal(t, "second", PanicsFrom(second)[0].Value)
+	require.Len(t, m.AllErrors(), 1)
+}
+
+func TestJoinErrors(t *testing.T) {
+	t.Parallel()
+
+	errInitial := errors.New("initial error")
+
+	errs := errInitial
+	m := NewGoroutineManagerWithSink(context.Background(), JoinErrors(&errs), GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	})
+	m.Wait()
+
+	// Verify the sink behaves like passing &errs to NewGoroutineManager.
+	require.ErrorIs(t, errs, errInitial)
+	require.ErrorIs(t, errs, testErr)
+	require.Len(t, errs.(interface{ Unwrap() []error }).Unwrap(), 2)
+}
+
+func TestJoinErrorsFlat(t *testing.T) {
+	t.Parallel()
+
+	errInitial := errors.New("initial error")
+
+	errs := errors.Join(errInitial, testErr)
+	sink := JoinErrors(&errs)
+
+	other := errors.New("other error")
+	for range 1000 {
+		sink.Add(testErr)
+	}
+	sink.Add(nil)
+	sink.Add(other)
+
+	// Verify the errors stay flat and in order, and nil errors are dropped like
+	// with errors.Join.
+	list := errs.(interface{ Unwrap() []error }).Unwrap()
+	require.Len(t, list, 1003)
+	require.Equal(t, errInitial, list[0])
+	require.Equal(t, other, list[1002])
+	require.ErrorIs(t, errs, other)
+	require.Equal(t, errors.Join(list...).Error(), errs.Error())
+}
+
+func TestJoinErrorsSnapshot(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	sink := JoinErrors(&errs)
+
+	sink.Add(testErr)
+	first := errs
+
+	// Verify errors read from errs don't change when more errors are added or
+	// the unwrapped list is modified.
+	first.(interface{ Unwrap() []error }).Unwrap()[0] = nil
+	sink.Add(errors.New("other error"))
+
+	require.Equal(t, []error{testErr}, first.(interface{ Unwrap() []error }).Unwrap())
+	require.Equal(t, testErr.Error(), first.Error())
+	require.Len(t, errs.(interface{ Unwrap() []error }).Unwrap(), 2)
+	require.Equal(t, testErr, errs.(interface{ Unwrap() []error }).Unwrap()[0])
+}
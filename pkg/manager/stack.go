@@ -0,0 +1,134 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// currentGoroutineID returns the runtime ID of the calling goroutine
+func currentGoroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	// The stack starts with a header like "goroutine 123 [running]:"
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+
+	id, err := strconv.ParseUint(string(buf), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
+// goroutineStacks returns the current stacks of the goroutines with the given
+// runtime IDs. Goroutines that have already exited are omitted.
+func goroutineStacks(ids ...uint64) map[uint64][]byte {
+	wanted := map[string]uint64{}
+	for _, id := range ids {
+		wanted["goroutine "+strconv.FormatUint(id, 10)+" "] = id
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := map[uint64][]byte{}
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		i := bytes.IndexByte(stack, '[')
+		if i < 0 {
+			continue
+		}
+
+		if id, ok := wanted[string(stack[:i])]; ok {
+			stacks[id] = append(stack, '\n')
+		}
+	}
+
+	return stacks
+}
+
+// Blocking returns the metadata of the foreground goroutines (and panic
+// collectors) that haven't finished yet, i.e. the ones that Wait() is waiting
+// for, ordered by their start time. Their current stacks are included if the
+// goroutine manager was created with WithBlockingStacks or WithWaitDumpAfter.
+func (m *GoroutineManager) Blocking() []GoroutineInfo {
+	remaining := m.tracker.remaining()
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].started.Before(remaining[j].started)
+	})
+
+	var stacks map[uint64][]byte
+	if m.recordsRuntimeIDs() {
+		ids := make([]uint64, 0, len(remaining))
+		for _, g := range remaining {
+			ids = append(ids, g.id.Load())
+		}
+		stacks = goroutineStacks(ids...)
+	}
+
+	now := m.options.clock.Now()
+
+	infos := make([]GoroutineInfo, 0, len(remaining))
+	for _, g := range remaining {
+		info := g.info(now)
+		info.Stack = stacks[g.id.Load()]
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// recordsRuntimeIDs returns true if the runtime IDs of goroutines are recorded,
+// which is needed to get their stacks
+func (m *GoroutineManager) recordsRuntimeIDs() bool {
+	return m.options.blockingStacks || (m.options.waitDumpAfter > 0 && m.options.waitDumpWriter != nil)
+}
+
+// dumpRemaining writes the stacks of the remaining foreground goroutines to w
+// after Wait() has been blocked for d
+func (m *GoroutineManager) dumpRemaining(w io.Writer, d time.Duration) {
+	blocking := m.Blocking()
+
+	var buf bytes.Buffer
+	manager := "goroutine manager"
+	if m.name != "" {
+		manager = fmt.Sprintf("goroutine manager %q", m.name)
+	}
+
+	fmt.Fprintf(&buf, "%v: Wait() blocked for %v, %v foreground goroutines remaining\n", manager, d, len(blocking))
+
+	for _, info := range blocking {
+		name := info.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+
+		fmt.Fprintf(&buf, "\n=== %v (running for %v) ===\n", name, info.Runtime)
+
+		if info.Stack != nil {
+			buf.Write(info.Stack)
+		} else {
+			buf.WriteString("(stack unavailable)\n")
+		}
+	}
+
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.String()
+}
+
+func blockedInTest(done chan any) {
+	<-done
+}
+
+func TestWithWaitDumpAfter(t *testing.T) {
+	t.Parallel()
+
+	var buf syncBuffer
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithWaitDumpAfter(10*time.Millisecond, &buf))
+
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		blockedInTest(done)
+	}, WithGoroutineName("stuck"))
+
+	waited := make(chan any)
+	go func() {
+		defer close(waited)
+
+		m.Wait()
+	}()
+
+	// Verify the stacks of the remaining goroutines are written once Wait() is blocked for too long.
+	require.Eventually(t, func() bool {
+		return buf.String() != ""
+	}, time.Second, time.Millisecond)
+
+	out := buf.String()
+	require.Contains(t, out, "1 foreground goroutines remaining")
+	require.Contains(t, out, "=== stuck (running for")
+	require.Contains(t, out, "blockedInTest")
+
+	close(done)
+	<-waited
+	require.NoError(t, errs)
+}
+
+func TestBlocking(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithBlockingStacks())
+
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		blockedInTest(done)
+	}, WithGoroutineName("stuck"))
+	m.StartForegroundGoroutine(func(_ context.Context) {}, WithGoroutineName("finished"))
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		blockedInTest(done)
+	}, WithGoroutineName("background"))
+
+	// Verify only unfinished foreground goroutines are returned, with their stacks.
+	var blocking []GoroutineInfo
+	require.Eventually(t, func() bool {
+		blocking = m.Blocking()
+
+		return len(blocking) == 1 && blocking[0].Stack != nil
+	}, time.Second, time.Millisecond)
+
+	require.Equal(t, "stuck", blocking[0].Name)
+	require.Contains(t, string(blocking[0].Stack), "blockedInTest")
+
+	close(done)
+	m.Wait()
+
+	require.Empty(t, m.Blocking())
+	require.NoError(t, errs)
+}
+
+func TestBlockingWithoutStacks(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		blockedInTest(done)
+	}, WithGoroutineName("stuck"))
+
+	// Verify stacks are omitted unless runtime IDs are recorded.
+	blocking := m.Blocking()
+	require.Len(t, blocking, 1)
+	require.Equal(t, "stuck", blocking[0].Name)
+	require.Nil(t, blocking[0].Stack)
+
+	close(done)
+	m.Wait()
+	require.NoError(t, errs)
+}
@@ -0,0 +1,154 @@
+package manager
+
+import "sync"
+
+// State is the lifecycle phase of a goroutine manager
+type State int
+
+const (
+	StateIdle     State = iota // No goroutines are running and the goroutine context is not cancelled
+	StateRunning               // Goroutines are running and the goroutine context is not cancelled
+	StateStopping              // The goroutine context is cancelled, but goroutines, the OnShutdown hook or cleanup functions are still running
+	StateStopped               // The goroutine context is cancelled and everything finished
+)
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+
+	case StateRunning:
+		return "running"
+
+	case StateStopping:
+		return "stopping"
+
+	case StateStopped:
+		return "stopped"
+
+	default:
+		return "unknown"
+	}
+}
+
+// lifecycle derives the state of a goroutine manager from its running
+// goroutines and shutdown progress
+type lifecycle struct {
+	lock sync.Mutex
+
+	running  int
+	stopping bool
+	shutDown bool
+
+	state State
+
+	subscribers []func(old, new State)
+	pending     []stateChange
+	dispatching bool
+}
+
+// stateChange is a transition that hasn't been sent to the subscribers yet
+type stateChange struct {
+	old State
+	new State
+}
+
+// update applies fn to the lifecycle and returns the state before and after
+func (l *lifecycle) update(fn func(l *lifecycle)) (old, new State) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	fn(l)
+
+	old = l.state
+
+	switch {
+	case !l.stopping && l.running == 0:
+		l.state = StateIdle
+
+	case !l.stopping:
+		l.state = StateRunning
+
+	case l.running == 0 && l.shutDown:
+		l.state = StateStopped
+
+	default:
+		l.state = StateStopping
+	}
+
+	if old != l.state && len(l.subscribers) > 0 {
+		l.pending = append(l.pending, stateChange{old, l.state})
+	}
+
+	return old, l.state
+}
+
+// dispatch sends pending state changes to the subscribers in order. Only one
+// caller dispatches at a time, so subscribers are called outside the lock
+// without reordering transitions, and may themselves cause transitions.
+func (l *lifecycle) dispatch() {
+	l.lock.Lock()
+	if l.dispatching {
+		l.lock.Unlock()
+
+		return
+	}
+	l.dispatching = true
+
+	for len(l.pending) > 0 {
+		change := l.pending[0]
+		l.pending = l.pending[1:]
+		subscribers := l.subscribers
+		l.lock.Unlock()
+
+		for _, fn := range subscribers {
+			fn(change.old, change.new)
+		}
+
+		l.lock.Lock()
+	}
+
+	l.dispatching = false
+	l.lock.Unlock()
+}
+
+// updateState applies fn to the lifecycle of the goroutine manager
+func (m *GoroutineManager) updateState(fn func(l *lifecycle)) {
+	if old, new := m.lifecycle.update(fn); old != new {
+		m.options.logger.Debug("goroutine manager state changed", "old", old.String(), "new", new.String())
+
+		m.trace(func() TraceEvent {
+			return TraceEvent{
+				Time:  m.options.clock.Now(),
+				Type:  TraceEventState,
+				State: new.String(),
+			}
+		})
+	}
+
+	m.lifecycle.dispatch()
+}
+
+// State returns the lifecycle phase of the goroutine manager. The possible
+// transitions are:
+//
+//	Idle <-> Running           Goroutines start or all of them finish
+//	Idle, Running -> Stopping  The goroutine context is cancelled
+//	Stopping -> Stopped        All goroutines, the OnShutdown hook and cleanup functions finished
+//	Stopped -> Stopping        A goroutine is started after stopping; it sees a cancelled context
+func (m *GoroutineManager) State() State {
+	m.lifecycle.lock.Lock()
+	defer m.lifecycle.lock.Unlock()
+
+	return m.lifecycle.state
+}
+
+// OnStateChange registers fn to be called with the old and new state each
+// time the goroutine manager transitions to another state. Transitions are
+// reported in order and one at a time.
+func (m *GoroutineManager) OnStateChange(fn func(old, new State)) {
+	m.lifecycle.lock.Lock()
+	defer m.lifecycle.lock.Unlock()
+
+	m.lifecycle.subscribers = append(m.lifecycle.subscribers, fn)
+}
@@ -0,0 +1,96 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestState(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	require.Equal(t, StateIdle, m.State())
+
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		<-done
+	})
+	require.Equal(t, StateRunning, m.State())
+
+	// Verify the manager is idle again once the goroutines finish.
+	close(done)
+	m.Wait()
+	require.Equal(t, StateIdle, m.State())
+
+	// Verify the manager is stopping while goroutines are finishing.
+	stop := make(chan any)
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		<-stop
+	})
+
+	m.StopAllGoroutines()
+	require.Eventually(t, func() bool {
+		return m.State() == StateStopping
+	}, time.Second, time.Millisecond)
+
+	// Verify the manager is stopped once everything finished.
+	close(stop)
+	require.Eventually(t, func() bool {
+		return m.State() == StateStopped
+	}, time.Second, time.Millisecond)
+	require.NoError(t, errs)
+}
+
+func TestStateStoppedWithoutGoroutines(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.Equal(t, StateStopped, m.State())
+	require.Equal(t, "stopped", m.State().String())
+}
+
+func TestOnStateChange(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		lock    sync.Mutex
+		changes []string
+	)
+	m.OnStateChange(func(old, new State) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		changes = append(changes, old.String()+" -> "+new.String())
+	})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {})
+	m.Wait()
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	// Verify all transitions are reported in order.
+	lock.Lock()
+	defer lock.Unlock()
+
+	require.Equal(t, []string{
+		"idle -> running",
+		"running -> idle",
+		"idle -> stopping",
+		"stopping -> stopped",
+	}, changes)
+	require.NoError(t, errs)
+}
@@ -0,0 +1,264 @@
+package manager
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets are the upper bounds of the buckets used for goroutine
+// duration histograms
+var DefaultDurationBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+}
+
+// DurationHistogram is a histogram of goroutine durations
+type DurationHistogram struct {
+	Buckets []time.Duration // Upper bounds of the buckets, in ascending order
+	Counts  []uint64        // Number of durations in each bucket; the last entry counts durations above the last bound
+	Count   uint64          // Total number of durations
+	Sum     time.Duration   // Sum of all durations
+}
+
+func newDurationHistogram() DurationHistogram {
+	return DurationHistogram{
+		Buckets: DefaultDurationBuckets,
+		Counts:  make([]uint64, len(DefaultDurationBuckets)+1),
+	}
+}
+
+func (h *DurationHistogram) observe(d time.Duration) {
+	h.Counts[sort.Search(len(h.Buckets), func(i int) bool {
+		return d <= h.Buckets[i]
+	})]++
+	h.Count++
+	h.Sum += d
+}
+
+func (h DurationHistogram) clone() DurationHistogram {
+	h.Buckets = append([]time.Duration{}, h.Buckets...)
+	h.Counts = append([]uint64{}, h.Counts...)
+
+	return h
+}
+
+// GoroutineCounts contains the number of goroutines in each phase of their
+// lifecycle
+type GoroutineCounts struct {
+	Started  uint64 // Total number of started goroutines
+	Running  uint64 // Number of goroutines that haven't finished yet
+	Finished uint64 // Total number of finished goroutines
+}
+
+// Stats contains statistics about the goroutines of a goroutine manager.
+// Panic collectors are counted as goroutines that run from the creation of the
+// collector until it is called.
+type Stats struct {
+	Foreground GoroutineCounts // Counts of foreground goroutines, which block Wait()
+	Background GoroutineCounts // Counts of background goroutines
+
+	Durations       DurationHistogram            // Durations of all finished goroutines
+	DurationsByName map[string]DurationHistogram // Durations of finished goroutines by name, or by group for workers started with StartWorkers; goroutines without a name are counted under ""
+
+	Queues map[string]QueueStats // Statistics of the queues created with NewQueue by name
+
+	Runtime *RuntimeSample // Latest sample of StartRuntimeMonitor(), or nil if it hasn't taken one yet
+}
+
+// stats collects statistics about the goroutines of a goroutine manager
+type stats struct {
+	lock sync.Mutex
+
+	foreground GoroutineCounts
+	background GoroutineCounts
+
+	durations       DurationHistogram
+	durationsByName map[string]*DurationHistogram
+
+	queues map[string]*Queue
+
+	runtime *RuntimeSample
+
+	recorder StatsRecorder
+
+	highWatermark      int  // Number of running goroutines above which the watermark is exceeded, or 0 if disabled
+	aboveHighWatermark bool // Whether the watermark is currently exceeded
+	onHighWatermark    func(running int, exceeded bool)
+}
+
+func newStats(recorder StatsRecorder, highWatermark int, onHighWatermark func(running int, exceeded bool)) *stats {
+	return &stats{
+		recorder: recorder,
+
+		highWatermark:   highWatermark,
+		onHighWatermark: onHighWatermark,
+
+		durations:       newDurationHistogram(),
+		durationsByName: map[string]*DurationHistogram{},
+
+		queues: map[string]*Queue{},
+	}
+}
+
+// addQueue registers a queue so that its statistics are included
+func (s *stats) addQueue(name string, q *Queue) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.queues[name] = q
+}
+
+// counts returns the counts for the kind of a goroutine. It must be called
+// with the lock held.
+func (s *stats) counts(g *goroutine) *GoroutineCounts {
+	if g.foreground {
+		return &s.foreground
+	}
+
+	return &s.background
+}
+
+// watermarkCrossing is a change of whether the number of running goroutines
+// exceeds the high watermark
+type watermarkCrossing struct {
+	running   int
+	watermark int
+	exceeded  bool
+	hook      func(running int, exceeded bool) // Hook configured at the time of the crossing
+}
+
+// checkHighWatermark returns the crossing of the high watermark caused by the
+// last start or finish or a change of the watermark, or nil if there is none.
+// It must be called with the lock held.
+func (s *stats) checkHighWatermark() *watermarkCrossing {
+	running := int(s.foreground.Running + s.background.Running)
+
+	// Disabling the watermark counts as recovering from it
+	exceeded := s.highWatermark > 0 && running > s.highWatermark
+	if exceeded != s.aboveHighWatermark {
+		s.aboveHighWatermark = exceeded
+
+		return &watermarkCrossing{running, s.highWatermark, exceeded, s.onHighWatermark}
+	}
+
+	return nil
+}
+
+// setHighWatermark changes the high watermark and its hook. It returns the
+// resulting crossing of the new watermark, if any.
+func (s *stats) setHighWatermark(n int, hook func(running int, exceeded bool)) *watermarkCrossing {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.highWatermark = n
+	s.onHighWatermark = hook
+
+	return s.checkHighWatermark()
+}
+
+// start records that a goroutine has started. It returns the resulting
+// crossing of the high watermark, if any.
+func (s *stats) start(g *goroutine) *watermarkCrossing {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	counts := s.counts(g)
+	counts.Started++
+	counts.Running++
+
+	labels := map[string]string{LabelKind: g.kind()}
+	s.recorder.AddCounter(MetricGoroutinesStarted, 1, labels)
+	s.recorder.SetGauge(MetricGoroutinesRunning, float64(counts.Running), labels)
+
+	return s.checkHighWatermark()
+}
+
+// finish records that a goroutine has finished at time now. It returns the
+// resulting crossing of the high watermark, if any.
+func (s *stats) finish(g *goroutine, now time.Time) *watermarkCrossing {
+	d := now.Sub(g.started)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	counts := s.counts(g)
+	counts.Running--
+	counts.Finished++
+
+	s.durations.observe(d)
+
+	h, ok := s.durationsByName[g.metricsName()]
+	if !ok {
+		v := newDurationHistogram()
+		h = &v
+
+		s.durationsByName[g.metricsName()] = h
+	}
+	h.observe(d)
+
+	labels := map[string]string{LabelKind: g.kind()}
+	s.recorder.AddCounter(MetricGoroutinesFinished, 1, labels)
+	s.recorder.SetGauge(MetricGoroutinesRunning, float64(counts.Running), labels)
+	s.recorder.ObserveHistogram(MetricGoroutineDuration, d.Seconds(), map[string]string{LabelGoroutine: g.metricsName()})
+
+	return s.checkHighWatermark()
+}
+
+// panic records that a panic was recovered from a goroutine
+func (s *stats) panic(g *goroutine) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.recorder.AddCounter(MetricPanics, 1, map[string]string{LabelGoroutine: g.metricsName()})
+}
+
+// setRuntime stores the latest runtime sample
+func (s *stats) setRuntime(sample RuntimeSample) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.runtime = &sample
+}
+
+func (s *stats) snapshot() Stats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	out := Stats{
+		Foreground: s.foreground,
+		Background: s.background,
+
+		Durations:       s.durations.clone(),
+		DurationsByName: map[string]DurationHistogram{},
+
+		Queues: map[string]QueueStats{},
+
+		Runtime: s.runtime,
+	}
+	for name, h := range s.durationsByName {
+		out.DurationsByName[name] = h.clone()
+	}
+
+	for name, q := range s.queues {
+		out.Queues[name] = q.Stats()
+	}
+
+	return out
+}
+
+// Stats returns a snapshot of the statistics about the goroutines of the
+// goroutine manager
+func (m *GoroutineManager) Stats() Stats {
+	return m.stats.snapshot()
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsDurations(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	for i := 0; i < 3; i++ {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			time.Sleep(20 * time.Millisecond)
+		}, WithGoroutineName("slow"))
+	}
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithPanicPolicy(PanicPolicyRecord))
+
+	func() {
+		defer m.CreateForegroundPanicCollector(WithGoroutineName("collector"))()
+	}()
+
+	m.Wait()
+
+	stats := m.Stats()
+
+	// Verify the overall histogram includes all goroutines.
+	require.Equal(t, uint64(5), stats.Durations.Count)
+	require.GreaterOrEqual(t, stats.Durations.Sum, 60*time.Millisecond)
+	require.Len(t, stats.Durations.Counts, len(stats.Durations.Buckets)+1)
+
+	// Verify the histograms by name.
+	require.Len(t, stats.DurationsByName, 3)
+	require.Equal(t, uint64(3), stats.DurationsByName["slow"].Count)
+	require.Equal(t, uint64(1), stats.DurationsByName[""].Count)
+	require.Equal(t, uint64(1), stats.DurationsByName["collector"].Count)
+
+	// Verify the durations were counted in the right buckets.
+	slow := stats.DurationsByName["slow"]
+	for i, bound := range slow.Buckets {
+		if bound < 20*time.Millisecond {
+			require.Zero(t, slow.Counts[i])
+		}
+	}
+}
+
+func TestDurationHistogramObserve(t *testing.T) {
+	t.Parallel()
+
+	h := newDurationHistogram()
+	h.observe(0)
+	h.observe(time.Millisecond)
+	h.observe(2 * time.Millisecond)
+	h.observe(time.Hour)
+
+	require.Equal(t, uint64(2), h.Counts[0])
+	require.Equal(t, uint64(1), h.Counts[1])
+	require.Equal(t, uint64(1), h.Counts[len(h.Counts)-1])
+	require.Equal(t, uint64(4), h.Count)
+	require.Equal(t, time.Hour+3*time.Millisecond, h.Sum)
+}
+
+func TestStatsCounts(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		<-done
+	})
+	m.StartForegroundGoroutine(func(_ context.Context) {})
+	m.StartBackgroundGoroutine(func(_ context.Context) {
+		<-done
+	})
+
+	// Verify foreground and background goroutines are counted separately.
+	require.Eventually(t, func() bool {
+		stats := m.Stats()
+
+		return stats.Foreground == GoroutineCounts{Started: 2, Running: 1, Finished: 1} &&
+			stats.Background == GoroutineCounts{Started: 1, Running: 1}
+	}, time.Second, time.Millisecond)
+
+	close(done)
+	m.Wait()
+
+	require.Equal(t, GoroutineCounts{Started: 2, Finished: 2}, m.Stats().Foreground)
+	require.Eventually(t, func() bool {
+		return m.Stats().Background == GoroutineCounts{Started: 1, Finished: 1}
+	}, time.Second, time.Millisecond)
+	require.NoError(t, errs)
+}
@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultStealInterval is how often idle workers look for tasks to steal if
+// WithWorkStealing is called with a zero interval
+const DefaultStealInterval = 10 * time.Millisecond
+
+// WithWorkStealing lets idle workers of the queue run tasks of other queues
+// of the same goroutine manager that have tasks waiting, which improves
+// utilization without merging the queues. Idle workers look for tasks to steal
+// every interval. Stolen tasks keep the options, e.g. the panic policy, of the
+// queue they were enqueued in. Tasks of sharded queues are never stolen, since
+// that would break their ordering.
+func WithWorkStealing(interval time.Duration) QueueOption {
+	return func(o *queueOptions) {
+		if interval <= 0 {
+			interval = DefaultStealInterval
+		}
+
+		o.stealInterval = interval
+	}
+}
+
+// queueList returns the queues of the goroutine manager
+func (s *stats) queueList() []*Queue {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	queues := make([]*Queue, 0, len(s.queues))
+	for _, q := range s.queues {
+		queues = append(queues, q)
+	}
+
+	return queues
+}
+
+// steal runs a task of the other queue with the most queued tasks, if any. It
+// returns false if there was nothing to steal.
+func (q *Queue) steal(ctx context.Context) bool {
+	var (
+		victim *Queue
+		depth  int
+	)
+	for _, other := range q.m.stats.queueList() {
+		if other == q || other.options.ordered {
+			continue
+		}
+
+		other.lock.Lock()
+		if other.depth > depth {
+			victim, depth = other, other.depth
+		}
+		other.lock.Unlock()
+	}
+
+	if victim == nil {
+		return false
+	}
+
+	select {
+	case <-victim.ready:
+		task := victim.pop()
+
+		victim.lock.Lock()
+		victim.stolen++
+		victim.lock.Unlock()
+
+		victim.run(ctx, task)
+
+		return true
+
+	default:
+		// Another worker took the task first
+		return false
+	}
+}
@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWorkStealing(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	busy := m.NewQueue("busy", 1, 10)
+	idle := m.NewQueue("idle", 2, 10, WithWorkStealing(time.Millisecond))
+
+	// Block the only worker of the busy queue.
+	started, release := make(chan struct{}), make(chan struct{})
+	require.NoError(t, busy.TryEnqueue(func(_ context.Context) {
+		close(started)
+
+		<-release
+	}))
+	<-started
+
+	done := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, busy.TryEnqueue(func(_ context.Context) {
+			done <- struct{}{}
+		}))
+	}
+
+	// Verify the idle queue's workers run the queued tasks of the busy queue.
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+
+	require.EqualValues(t, 3, busy.Stats().Stolen)
+	require.EqualValues(t, 0, idle.Stats().Stolen)
+
+	close(release)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type storageKey struct{}
+
+// storage holds the values scoped to a managed goroutine
+type storage struct {
+	lock   sync.Mutex
+	values map[any]any
+	keys   []any // Keys in the order they were first set
+	closed bool
+}
+
+// SetGoroutineValue stores value under key in the storage of the managed
+// goroutine that ctx belongs to. The storage is discarded when the goroutine
+// exits, and values that implement io.Closer are closed in the reverse order
+// they were set, e.g. per-worker buffers or connections. Errors returned by
+// Close are collected. Values that are replaced are not closed. It returns
+// false if ctx doesn't belong to a managed goroutine or the goroutine has
+// already exited.
+func SetGoroutineValue(ctx context.Context, key, value any) bool {
+	s, ok := ctx.Value(storageKey{}).(*storage)
+	if !ok {
+		return false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	if s.values == nil {
+		s.values = map[any]any{}
+	}
+
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+
+	s.values[key] = value
+
+	return true
+}
+
+// GoroutineValue returns the value stored under key in the storage of the
+// managed goroutine that ctx belongs to, see SetGoroutineValue()
+func GoroutineValue(ctx context.Context, key any) (any, bool) {
+	s, ok := ctx.Value(storageKey{}).(*storage)
+	if !ok {
+		return nil, false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, ok := s.values[key]
+
+	return value, ok
+}
+
+// close discards the stored values and closes the ones that implement
+// io.Closer, returning the errors they returned
+func (s *storage) close() []error {
+	s.lock.Lock()
+	values, keys := s.values, s.keys
+	s.values, s.keys, s.closed = nil, nil, true
+	s.lock.Unlock()
+
+	var errs []error
+	for i := len(keys) - 1; i >= 0; i-- {
+		if c, ok := values[keys[i]].(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("could not close goroutine value %v: %w", keys[i], err))
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestGoroutineValue(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, SetGoroutineValue(context.Background(), "key", 1))
+
+	_, ok := GoroutineValue(context.Background(), "key")
+	require.False(t, ok)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		goroutineCtx context.Context
+		value        any
+	)
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		goroutineCtx = ctx
+
+		SetGoroutineValue(ctx, "key", 1)
+		value, _ = GoroutineValue(ctx, "key")
+	})
+	m.Wait()
+
+	require.Equal(t, 1, value)
+
+	// Verify the storage is discarded once the goroutine exits.
+	require.False(t, SetGoroutineValue(goroutineCtx, "key", 2))
+
+	_, ok = GoroutineValue(goroutineCtx, "key")
+	require.False(t, ok)
+}
+
+func TestGoroutineValueClose(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var closed []string
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		SetGoroutineValue(ctx, "first", closerFunc(func() error {
+			closed = append(closed, "first")
+
+			return nil
+		}))
+		SetGoroutineValue(ctx, "second", closerFunc(func() error {
+			closed = append(closed, "second")
+
+			return testErr
+		}))
+
+		panic("test")
+	})
+	m.Wait()
+
+	// Verify values are closed in reverse order even after a panic, and close
+	// errors are collected.
+	require.Equal(t, []string{"second", "first"}, closed)
+	require.ErrorIs(t, errs, testErr)
+	require.Len(t, m.AllErrors(), 2)
+}
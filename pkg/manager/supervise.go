@@ -0,0 +1,201 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrSupervisedReload = errors.New("supervised goroutine reloaded") // Context cause of the attempts of supervised goroutines that are restarted by RestartSupervised()
+)
+
+// restartBudget is a token bucket limiting the restarts of all supervised
+// goroutines of a goroutine manager
+type restartBudget struct {
+	lock sync.Mutex
+
+	burst    int
+	interval time.Duration
+
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token at time now and returns how long to wait until the
+// token is available
+func (b *restartBudget) reserve(now time.Time) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.last.IsZero() {
+		b.tokens = float64(b.burst)
+	} else {
+		b.tokens = min(b.tokens+float64(now.Sub(b.last))/float64(b.interval), float64(b.burst))
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens * float64(b.interval))
+}
+
+// Starts a goroutine that can be waited for to finish and restarts fn if it
+// panics, waiting for the delay of the backoff set with WithBackoff
+// (DefaultBackoff by default) between attempts. Panics are collected, but
+// don't stop other goroutines. The goroutine finishes once fn returns without
+// panicking, the goroutine context is cancelled or the restarts set with
+// WithMaxRestarts are exhausted. If a restart budget is set with
+// WithRestartBudget, restarts additionally wait for it. With WithDedupWindow,
+// tasks run with RunOnce() aren't run again after a restart.
+//
+// RestartSupervised() restarts fn right away with a fresh context, e.g. to
+// reload its configuration.
+func (m *GoroutineManager) StartSupervisedGoroutine(fn func(context.Context), opts ...StartOption) {
+	options := newStartOptions(opts)
+
+	backoff := options.backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	attemptOpts := append(opts[:len(opts):len(opts)], WithPanicPolicy(PanicPolicyRecord), WithRuntimeErrorPolicy(PanicPolicyRecord))
+
+	s := &supervision{
+		status: SupervisionStatus{
+			Name: options.name,
+		},
+	}
+
+	m.health.addSupervision(s)
+
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		// The completed tasks are shared across attempts
+		if options.dedupWindow > 0 {
+			ctx = context.WithValue(ctx, dedupKey{}, newDedup(m.options.clock, options.dedupWindow))
+		}
+
+		for attempt := 1; ; attempt++ {
+			attemptCtx, reload := context.WithCancelCause(ctx)
+			m.health.setReload(s, reload)
+
+			panicked := m.runAttempt(attemptCtx, fn, attemptOpts)
+
+			m.health.setReload(s, nil)
+			reloaded := ctx.Err() == nil && errors.Is(context.Cause(attemptCtx), ErrSupervisedReload)
+			reload(nil)
+
+			if reloaded {
+				m.health.updateSupervision(s, func(status *SupervisionStatus) {
+					status.Reloads++
+				})
+
+				// Reloads don't count towards the restarts
+				attempt--
+
+				continue
+			}
+
+			if !panicked {
+				m.health.removeSupervision(s)
+
+				return
+			}
+
+			if options.maxRestarts > 0 && attempt > options.maxRestarts {
+				// Keep reporting the goroutine so that health reports show it won't be restarted
+				m.health.updateSupervision(s, func(status *SupervisionStatus) {
+					status.Exhausted = true
+				})
+
+				return
+			}
+
+			delay := backoff.Delay(attempt)
+			if m.restartBudget != nil {
+				delay = max(delay, m.restartBudget.reserve(m.options.clock.Now()))
+			}
+
+			timer := m.options.clock.NewTimer(delay)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				m.health.removeSupervision(s)
+
+				return
+
+			case <-timer.C():
+				m.health.updateSupervision(s, func(status *SupervisionStatus) {
+					status.Restarts++
+				})
+			}
+		}
+	}, opts...)
+}
+
+// runAttempt calls fn with a panic collector and returns true if it panicked
+// and should be restarted
+func (m *GoroutineManager) runAttempt(ctx context.Context, fn func(context.Context), opts []StartOption) (panicked bool) {
+	defer func() {
+		panicked = panicked && ctx.Err() == nil
+	}()
+
+	defer m.CreateBackgroundPanicCollector(opts...)()
+
+	panicked = true
+
+	fn(ctx)
+
+	return false
+}
+
+// RestartSupervised cancels the contexts of the running attempts of all
+// supervised goroutines with ErrSupervisedReload and restarts them right away
+// with fresh contexts, e.g. to reload their configuration without restarting
+// the process. Reloads don't count towards WithMaxRestarts and don't wait for
+// the backoff. It returns the number of goroutines that are restarted.
+func (m *GoroutineManager) RestartSupervised() int {
+	n := m.health.reloadSupervised(ErrSupervisedReload)
+
+	m.options.logger.Info("restarting supervised goroutines", "goroutines", n)
+
+	return n
+}
+
+// RestartSupervisedOnSignal calls RestartSupervised() whenever the process
+// receives one of sigs, SIGHUP by default, until the goroutine context is
+// cancelled. The signals are handled by a background goroutine.
+func (m *GoroutineManager) RestartSupervisedOnSignal(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	// Registered before returning, so that signals sent afterwards can't
+	// terminate the process
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sigs...)
+
+	m.StartBackgroundGoroutine(func(ctx context.Context) {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case sig := <-signals:
+				m.options.logger.Info("received signal", "signal", sig)
+
+				m.RestartSupervised()
+			}
+		}
+	}, WithGoroutineName("supervised restart signal handler"))
+}
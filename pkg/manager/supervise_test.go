@@ -0,0 +1,190 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisedGoroutine(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var attempts atomic.Uint64
+	m.StartSupervisedGoroutine(func(_ context.Context) {
+		if attempts.Add(1) < 3 {
+			panic(testErr)
+		}
+	}, WithBackoff(ConstantBackoff(time.Millisecond)))
+
+	// Verify fn is restarted until it returns without panicking, and the
+	// panics are collected without stopping other goroutines.
+	m.Wait()
+	require.Equal(t, uint64(3), attempts.Load())
+	require.ErrorIs(t, errs, testErr)
+	require.Len(t, PanicsFrom(errs), 2)
+	require.NoError(t, m.Context().Err())
+}
+
+func TestSupervisedGoroutineMaxRestarts(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var attempts atomic.Uint64
+	m.StartSupervisedGoroutine(func(_ context.Context) {
+		attempts.Add(1)
+
+		panic(testErr)
+	}, WithBackoff(ConstantBackoff(time.Millisecond)), WithMaxRestarts(2))
+
+	// Verify fn is called once and restarted twice.
+	m.Wait()
+	require.Equal(t, uint64(3), attempts.Load())
+}
+
+func TestSupervisedGoroutineStopped(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	started := make(chan any)
+	var attempts atomic.Uint64
+	m.StartSupervisedGoroutine(func(_ context.Context) {
+		if attempts.Add(1) == 1 {
+			close(started)
+		}
+
+		panic(testErr)
+	}, WithBackoff(ConstantBackoff(time.Hour)))
+
+	// Verify the goroutine doesn't wait for the backoff once stopped.
+	<-started
+	m.StopAllGoroutines()
+	m.Wait()
+	require.Equal(t, uint64(1), attempts.Load())
+}
+
+func TestRestartBudget(t *testing.T) {
+	t.Parallel()
+
+	budget := &restartBudget{burst: 2, interval: time.Second}
+	now := time.Now()
+
+	// Verify the burst is available immediately and later restarts wait.
+	require.Zero(t, budget.reserve(now))
+	require.Zero(t, budget.reserve(now))
+	require.Equal(t, time.Second, budget.reserve(now))
+	require.Equal(t, 2*time.Second, budget.reserve(now))
+
+	// Verify tokens are refilled over time.
+	require.Equal(t, time.Second, budget.reserve(now.Add(2*time.Second)))
+}
+
+func TestSupervisedGoroutineRestartBudget(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithRestartBudget(1, time.Hour))
+
+	var attempts atomic.Uint64
+	for i := 0; i < 2; i++ {
+		m.StartSupervisedGoroutine(func(_ context.Context) {
+			attempts.Add(1)
+
+			panic(testErr)
+		}, WithBackoff(ConstantBackoff(time.Millisecond)))
+	}
+
+	// Verify only one restart is allowed across both goroutines.
+	require.Eventually(t, func() bool {
+		return attempts.Load() == 3
+	}, time.Second, time.Millisecond)
+	require.Never(t, func() bool {
+		return attempts.Load() > 3
+	}, 50*time.Millisecond, time.Millisecond)
+
+	m.StopAllGoroutines()
+	m.Wait()
+}
+
+func TestRestartSupervised(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	causes := make(chan error, 2)
+	m.StartSupervisedGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+
+		causes <- context.Cause(ctx)
+	}, WithGoroutineName("reloadable"), WithMaxRestarts(1))
+
+	// Verify the running attempt is restarted with a fresh context.
+	require.Eventually(t, func() bool {
+		return m.RestartSupervised() == 1
+	}, time.Second, time.Millisecond)
+	require.ErrorIs(t, <-causes, ErrSupervisedReload)
+
+	require.Eventually(t, func() bool {
+		supervised := m.Health().Supervised
+
+		return len(supervised) == 1 && supervised[0].Reloads == 1
+	}, time.Second, time.Millisecond)
+	require.Zero(t, m.Health().Supervised[0].Restarts)
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.ErrorIs(t, <-causes, m.GetErrGoroutineStopped())
+	require.NoError(t, errs)
+}
+
+func TestRestartSupervisedOnSignal(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX signals")
+	}
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.RestartSupervisedOnSignal()
+
+	var attempts atomic.Uint64
+	m.StartSupervisedGoroutine(func(ctx context.Context) {
+		attempts.Add(1)
+
+		<-ctx.Done()
+	})
+
+	require.Eventually(t, func() bool {
+		return attempts.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	// Verify the signal restarts the supervised goroutine.
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGHUP))
+
+	require.Eventually(t, func() bool {
+		return attempts.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.NoError(t, errs)
+}
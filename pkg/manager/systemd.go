@@ -0,0 +1,131 @@
+package manager
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	systemdNotifySocket = "NOTIFY_SOCKET"
+	systemdWatchdogUsec = "WATCHDOG_USEC"
+	systemdWatchdogPID  = "WATCHDOG_PID"
+)
+
+// SystemdNotifier sends the service state of a goroutine manager to systemd
+// with the sd_notify protocol, see StartSystemdNotifier()
+type SystemdNotifier struct {
+	m      *GoroutineManager
+	socket string // Empty if the process isn't run by systemd
+
+	readyOnce sync.Once
+}
+
+// Starts sending the service state of the goroutine manager to systemd, for
+// services with Type=notify: a background goroutine sends READY=1 once a
+// goroutine with each of readyNames has called Ready(), see WaitReady(), and
+// STOPPING=1 is sent once the goroutine context is cancelled, e.g. by
+// StopAllGoroutines(). If the service has WatchdogSec set, a background
+// goroutine sends WATCHDOG=1 at half the watchdog interval while Health()
+// reports the manager as healthy, so that systemd restarts it once it becomes
+// unhealthy. If readyNames is empty, call Ready() on the returned notifier to
+// send READY=1 instead.
+//
+// If the process isn't run by systemd, i.e. $NOTIFY_SOCKET is not set, no
+// notifications are sent. Failed notifications are logged.
+//
+// Usage:
+//
+//	m.StartForegroundGoroutine(func(ctx context.Context) {
+//		listener := listen()
+//		manager.Ready(ctx)
+//
+//		serve(ctx, listener)
+//	}, manager.WithGoroutineName("server"))
+//
+//	m.StartSystemdNotifier([]string{"server"})
+func (m *GoroutineManager) StartSystemdNotifier(readyNames []string, opts ...StartOption) *SystemdNotifier {
+	var watchdog time.Duration
+	if pid := os.Getenv(systemdWatchdogPID); pid == "" || pid == strconv.Itoa(os.Getpid()) {
+		if usec, err := strconv.ParseInt(os.Getenv(systemdWatchdogUsec), 10, 64); err == nil && usec > 0 {
+			watchdog = time.Duration(usec) * time.Microsecond
+		}
+	}
+
+	return m.startSystemdNotifier(os.Getenv(systemdNotifySocket), watchdog, readyNames, opts)
+}
+
+// startSystemdNotifier starts a notifier for socket and the watchdog interval,
+// if it is not zero
+func (m *GoroutineManager) startSystemdNotifier(socket string, watchdog time.Duration, readyNames []string, opts []StartOption) *SystemdNotifier {
+	n := &SystemdNotifier{
+		m:      m,
+		socket: socket,
+	}
+
+	if socket == "" {
+		return n
+	}
+
+	m.OnStop(func(_ error) {
+		n.notify("STOPPING=1")
+	})
+
+	if len(readyNames) > 0 {
+		m.StartBackgroundGoroutine(func(ctx context.Context) {
+			if err := m.WaitReady(ctx, readyNames...); err != nil {
+				m.options.logger.Warn("startup goroutines didn't become ready, not sending systemd readiness notification", "error", err)
+
+				return
+			}
+
+			n.Ready()
+		}, append([]StartOption{WithGoroutineName("systemd readiness")}, opts...)...)
+	}
+
+	if watchdog > 0 {
+		m.StartPeriodicGoroutine(watchdog/2, func(_ context.Context) {
+			if !m.Health().Healthy {
+				m.options.logger.Warn("goroutine manager is unhealthy, skipping systemd watchdog notification")
+
+				return
+			}
+
+			n.notify("WATCHDOG=1")
+		}, append([]StartOption{WithGoroutineName("systemd watchdog"), WithImmediateStart(), WithBackground()}, opts...)...)
+	}
+
+	return n
+}
+
+// Ready sends READY=1 to systemd, so that it considers the service started.
+// Only the first call sends a notification, including the one made once the
+// readiness names passed to StartSystemdNotifier() are ready.
+func (n *SystemdNotifier) Ready() {
+	n.readyOnce.Do(func() {
+		n.notify("READY=1")
+	})
+}
+
+// notify sends state to the notification socket
+func (n *SystemdNotifier) notify(state string) {
+	if n.socket == "" {
+		return
+	}
+
+	// Abstract socket names starting with @ are handled by the net package
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: n.socket, Net: "unixgram"})
+	if err != nil {
+		n.m.options.logger.Warn("could not send systemd notification", "state", state, "error", err)
+
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		n.m.options.logger.Warn("could not send systemd notification", "state", state, "error", err)
+	}
+}
@@ -0,0 +1,183 @@
+package manager
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listenSystemd listens on a notification socket and returns its path and the
+// received notifications
+func listenSystemd(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram sockets are not supported on Windows")
+	}
+
+	// Temporary test directories can exceed the maximum socket path length
+	dir, err := os.MkdirTemp("", "systemd")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	notifications := make(chan string, 100)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+
+			notifications <- string(buf[:n])
+		}
+	}()
+
+	return socket, notifications
+}
+
+func TestStartSystemdNotifier(t *testing.T) {
+	t.Parallel()
+
+	socket, notifications := listenSystemd(t)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	n := m.startSystemdNotifier(socket, time.Hour, nil, nil)
+
+	// Verify the watchdog is notified immediately while the manager is healthy.
+	require.Equal(t, "WATCHDOG=1", <-notifications)
+
+	// Verify READY=1 is only sent once.
+	n.Ready()
+	n.Ready()
+	require.Equal(t, "READY=1", <-notifications)
+
+	// Verify stopping sends STOPPING=1.
+	m.StopAllGoroutines()
+	m.Wait()
+	require.Equal(t, "STOPPING=1", <-notifications)
+
+	select {
+	case notification := <-notifications:
+		require.Failf(t, "unexpected notification", "%v", notification)
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.NoError(t, errs)
+}
+
+func TestStartSystemdNotifierUnhealthy(t *testing.T) {
+	t.Parallel()
+
+	socket, notifications := listenSystemd(t)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	m.health.recordFatal()
+
+	// Verify the watchdog isn't notified while the manager is unhealthy.
+	m.startSystemdNotifier(socket, 20*time.Millisecond, nil, nil).Ready()
+	require.Equal(t, "READY=1", <-notifications)
+
+	select {
+	case notification := <-notifications:
+		require.Failf(t, "unexpected notification", "%v", notification)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestStartSystemdNotifierWithoutSocket(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify no goroutines are started and notifications are no-ops.
+	m.startSystemdNotifier("", time.Millisecond, []string{"server"}, nil).Ready()
+	require.Zero(t, m.Stats().Background.Running)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestStartSystemdNotifierReadyNames(t *testing.T) {
+	t.Parallel()
+
+	socket, notifications := listenSystemd(t)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	m.startSystemdNotifier(socket, 0, []string{"server", "worker"}, nil)
+
+	ready := make(chan struct{})
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		Ready(ctx)
+
+		<-ctx.Done()
+	}, WithGoroutineName("server"))
+	m.StartForegroundGoroutine(func(ctx context.Context) {
+		<-ready
+		Ready(ctx)
+
+		<-ctx.Done()
+	}, WithGoroutineName("worker"))
+
+	// Verify READY=1 isn't sent before all goroutines are ready.
+	select {
+	case notification := <-notifications:
+		require.Failf(t, "unexpected notification", "%v", notification)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(ready)
+	require.Equal(t, "READY=1", <-notifications)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.Equal(t, "STOPPING=1", <-notifications)
+	require.NoError(t, errs)
+}
+
+func TestStartSystemdNotifierNotReady(t *testing.T) {
+	t.Parallel()
+
+	socket, notifications := listenSystemd(t)
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+	m.startSystemdNotifier(socket, 0, []string{"server"}, nil)
+
+	// Verify READY=1 isn't sent if the goroutine finishes without being ready.
+	m.StartForegroundGoroutine(func(_ context.Context) {}, WithGoroutineName("server"))
+	m.Wait()
+
+	m.StopAllGoroutines()
+	require.Equal(t, "STOPPING=1", <-notifications)
+
+	select {
+	case notification := <-notifications:
+		require.Failf(t, "unexpected notification", "%v", notification)
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.NoError(t, errs)
+}
@@ -0,0 +1,169 @@
+package manager
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+)
+
+// tagLimit is a semaphore for the goroutines with a tag, whose limit can be
+// changed while goroutines hold it
+type tagLimit struct {
+	lock    sync.Mutex
+	limit   int
+	running int
+	changed chan struct{} // Closed and replaced whenever a slot may have become available
+}
+
+// tryAcquire takes a slot if one is available. Otherwise, it returns a channel
+// that is closed once one may have become available.
+func (l *tagLimit) tryAcquire() (bool, <-chan struct{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.running < l.limit {
+		l.running++
+
+		return true, nil
+	}
+
+	return false, l.changed
+}
+
+// release returns a slot
+func (l *tagLimit) release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.running--
+	l.notify()
+}
+
+// setLimit changes the limit; goroutines holding slots above the new limit
+// keep running
+func (l *tagLimit) setLimit(limit int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.limit = limit
+	l.notify()
+}
+
+// notify wakes up waiting goroutines. It must be called with the lock held.
+func (l *tagLimit) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// tagLimits holds a semaphore for each tag with a concurrency limit
+type tagLimits struct {
+	lock   sync.RWMutex
+	limits map[string]*tagLimit
+}
+
+func newTagLimits(limits map[string]int) *tagLimits {
+	t := &tagLimits{
+		limits: map[string]*tagLimit{},
+	}
+	for tag, limit := range limits {
+		t.set(tag, limit)
+	}
+
+	return t
+}
+
+// set changes the limit of a tag; a limit of zero or less removes it
+func (t *tagLimits) set(tag string, limit int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	l, ok := t.limits[tag]
+	if limit <= 0 {
+		if ok {
+			// Goroutines waiting for the removed limit may run right away
+			delete(t.limits, tag)
+			l.setLimit(math.MaxInt)
+		}
+
+		return
+	}
+
+	if !ok {
+		t.limits[tag] = &tagLimit{
+			limit:   limit,
+			changed: make(chan struct{}),
+		}
+
+		return
+	}
+
+	l.setLimit(limit)
+}
+
+// acquire waits until the goroutine with tags may run under the limits of its
+// tags or ctx is done. Slots are acquired in the order of the tag names so that
+// goroutines with overlapping tags can't deadlock. It returns a function that
+// releases the slots, or false if ctx is done first.
+func (t *tagLimits) acquire(ctx context.Context, tags []string) (func(), bool) {
+	sorted := slices.Clone(tags)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var limits []*tagLimit
+
+	t.lock.RLock()
+	for _, tag := range sorted {
+		if l, ok := t.limits[tag]; ok {
+			limits = append(limits, l)
+		}
+	}
+	t.lock.RUnlock()
+
+	if len(limits) == 0 {
+		return func() {}, true
+	}
+
+	release := func(acquired []*tagLimit) {
+		for _, l := range acquired {
+			l.release()
+		}
+	}
+
+	for i, l := range limits {
+		for {
+			ok, changed := l.tryAcquire()
+			if ok {
+				break
+			}
+
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				release(limits[:i])
+
+				return nil, false
+			}
+		}
+	}
+
+	// The slot might have become available as ctx was done
+	if ctx.Err() != nil {
+		release(limits)
+
+		return nil, false
+	}
+
+	return func() {
+		release(limits)
+	}, true
+}
+
+// SetTagLimit changes the limit of the goroutines tagged with tag that run at
+// once on a live goroutine manager, see WithTagLimit(). A limit of zero or less
+// removes it. Lowering a limit doesn't affect goroutines that are already
+// running, while goroutines waiting for a slot are started once the new limit
+// allows it.
+func (m *GoroutineManager) SetTagLimit(tag string, limit int) {
+	m.tagLimits.set(tag, limit)
+}
@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTagLimit(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithTagLimit("upload", 2))
+
+	var (
+		running, maxRunning atomic.Int64
+		unlimited           atomic.Int64
+	)
+	release := make(chan struct{})
+
+	for range 5 {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			n := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				current := maxRunning.Load()
+				if n <= current || maxRunning.CompareAndSwap(current, n) {
+					break
+				}
+			}
+
+			<-release
+		}, WithTags("upload", "upload"))
+	}
+
+	for range 3 {
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			unlimited.Add(1)
+
+			<-release
+		}, WithTags("download"))
+	}
+
+	// Verify only two tagged goroutines run at once, while others aren't limited.
+	require.Eventually(t, func() bool {
+		return running.Load() == 2 && unlimited.Load() == 3
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	m.Wait()
+
+	require.NoError(t, errs)
+	require.EqualValues(t, 2, maxRunning.Load())
+}
+
+func TestWithTagLimitStopped(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithTagLimit("upload", 1))
+
+	var ran atomic.Int64
+	for range 3 {
+		m.StartForegroundGoroutine(func(ctx context.Context) {
+			ran.Add(1)
+
+			<-ctx.Done()
+		}, WithTags("upload"))
+	}
+
+	require.Eventually(t, func() bool {
+		return ran.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	// Verify waiting goroutines exit without running once they are stopped.
+	m.StopAllGoroutines()
+	m.Wait()
+
+	require.EqualValues(t, 1, ran.Load())
+}
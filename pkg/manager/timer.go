@@ -0,0 +1,173 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// managedTimer is the implementation of ManagedTimer and ManagedTicker. It
+// fires once after the initial duration, and then every period if period is
+// positive.
+type managedTimer struct {
+	lock sync.Mutex
+
+	clock  Clock
+	timer  Timer
+	period time.Duration
+
+	c       chan time.Time
+	done    chan struct{} // Closed once the timer is stopped
+	stopped bool
+
+	stopAfter func() bool // Deregisters the automatic stop with the goroutine context
+}
+
+func (m *GoroutineManager) newManagedTimer(d, period time.Duration) *managedTimer {
+	t := &managedTimer{
+		clock:  m.options.clock,
+		period: period,
+
+		c:    make(chan time.Time, 1),
+		done: make(chan struct{}),
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.timer = t.clock.AfterFunc(d, t.fire)
+	t.stopAfter = context.AfterFunc(m.internalCtx, func() {
+		t.stop()
+	})
+
+	return t
+}
+
+// fire sends the current time without blocking, dropping it if the previous
+// one wasn't received yet, like time.Ticker
+func (t *managedTimer) fire() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.stopped {
+		return
+	}
+
+	select {
+	case t.c <- t.clock.Now():
+	default:
+	}
+
+	if t.period > 0 {
+		t.timer.Reset(t.period)
+	}
+}
+
+// stop stops the timer and closes its done channel. It returns false if the
+// timer was already stopped.
+func (t *managedTimer) stop() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+
+	t.timer.Stop()
+	t.stopAfter()
+	close(t.done)
+
+	return true
+}
+
+// reset changes the timer to fire after d, and then every period if period is
+// positive. It returns false if the timer was already stopped.
+func (t *managedTimer) reset(d, period time.Duration) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.stopped {
+		return false
+	}
+
+	t.period = period
+	t.timer.Reset(d)
+
+	return true
+}
+
+// ManagedTimer is a timer that is stopped once the goroutine context is
+// cancelled. Create one with NewTimer.
+type ManagedTimer struct {
+	t *managedTimer
+}
+
+// NewTimer creates a timer that sends the current time on its channel after d,
+// using the manager's clock. Once the goroutine context is cancelled, the
+// timer is stopped and its Done() channel is closed, so that loops waiting for
+// it can't outlive the manager.
+func (m *GoroutineManager) NewTimer(d time.Duration) *ManagedTimer {
+	return &ManagedTimer{m.newManagedTimer(d, 0)}
+}
+
+// C returns the channel the time is sent on
+func (t *ManagedTimer) C() <-chan time.Time {
+	return t.t.c
+}
+
+// Done returns a channel that is closed once the timer is stopped, either by
+// Stop() or by cancellation of the goroutine context
+func (t *ManagedTimer) Done() <-chan struct{} {
+	return t.t.done
+}
+
+// Stop stops the timer and closes its Done() channel. It returns false if the
+// timer was already stopped.
+func (t *ManagedTimer) Stop() bool {
+	return t.t.stop()
+}
+
+// Reset changes the timer to fire after d, even if it already fired. It
+// returns false if the timer was stopped, in which case it can't be reset.
+func (t *ManagedTimer) Reset(d time.Duration) bool {
+	return t.t.reset(d, 0)
+}
+
+// ManagedTicker is a ticker that is stopped once the goroutine context is
+// cancelled. Create one with NewTicker.
+type ManagedTicker struct {
+	t *managedTimer
+}
+
+// NewTicker creates a ticker that sends the current time on its channel every
+// d, using the manager's clock. Like time.Ticker, ticks are dropped for slow
+// receivers. Once the goroutine context is cancelled, the ticker is stopped
+// and its Done() channel is closed, so that periodic loops can't leak it past
+// the shutdown.
+func (m *GoroutineManager) NewTicker(d time.Duration) *ManagedTicker {
+	return &ManagedTicker{m.newManagedTimer(d, d)}
+}
+
+// C returns the channel the ticks are sent on
+func (t *ManagedTicker) C() <-chan time.Time {
+	return t.t.c
+}
+
+// Done returns a channel that is closed once the ticker is stopped, either by
+// Stop() or by cancellation of the goroutine context
+func (t *ManagedTicker) Done() <-chan struct{} {
+	return t.t.done
+}
+
+// Stop stops the ticker and closes its Done() channel. It returns false if the
+// ticker was already stopped.
+func (t *ManagedTicker) Stop() bool {
+	return t.t.stop()
+}
+
+// Reset changes the ticker's period to d and restarts it. It returns false if
+// the ticker was stopped, in which case it can't be reset.
+func (t *ManagedTicker) Reset(d time.Duration) bool {
+	return t.t.reset(d, d)
+}
@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTimer(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	timer := m.NewTimer(time.Millisecond)
+	<-timer.C()
+
+	require.True(t, timer.Reset(time.Millisecond))
+	<-timer.C()
+
+	require.True(t, timer.Stop())
+	require.False(t, timer.Stop())
+	require.False(t, timer.Reset(time.Millisecond))
+	<-timer.Done()
+}
+
+func TestNewTimerStopped(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	timer := m.NewTimer(time.Hour)
+
+	// Verify stopping the manager stops the timer and signals its loop.
+	m.StopAllGoroutines()
+
+	select {
+	case <-timer.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timer not stopped")
+	}
+	require.False(t, timer.Stop())
+}
+
+func TestNewTicker(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	ticker := m.NewTicker(time.Millisecond)
+
+	ticks := 0
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		for {
+			select {
+			case <-ticker.C():
+				ticks++
+
+				if ticks == 3 {
+					m.StopAllGoroutines()
+				}
+			case <-ticker.Done():
+				return
+			}
+		}
+	})
+	m.Wait()
+
+	// Verify the loop ends once the manager stops the ticker.
+	require.GreaterOrEqual(t, ticks, 3)
+	require.False(t, ticker.Stop())
+	require.False(t, ticker.Reset(time.Millisecond))
+}
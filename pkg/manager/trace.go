@@ -0,0 +1,161 @@
+package manager
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// TraceEventType is the type of a lifecycle event in a trace
+type TraceEventType string
+
+const (
+	TraceEventStart  TraceEventType = "start"  // A goroutine or panic collector was started
+	TraceEventFinish TraceEventType = "finish" // A goroutine or panic collector finished
+	TraceEventPanic  TraceEventType = "panic"  // A panic was recovered from a goroutine
+	TraceEventCancel TraceEventType = "cancel" // The goroutine context was cancelled
+	TraceEventState  TraceEventType = "state"  // The goroutine manager transitioned to another state
+)
+
+// TraceEvent is a lifecycle event recorded with WithTrace
+type TraceEvent struct {
+	Time       time.Time      `json:"time"`
+	Type       TraceEventType `json:"type"`
+	ID         uint64         `json:"id,omitempty"`         // ID of the goroutine, see GoroutineIDFromContext
+	Name       string         `json:"name,omitempty"`       // Name of the goroutine
+	Tags       []string       `json:"tags,omitempty"`       // Tags of the goroutine
+	Foreground bool           `json:"foreground,omitempty"` // Whether the goroutine blocks Wait()
+	Runtime    time.Duration  `json:"runtime,omitempty"`    // How long the goroutine ran, for finish events
+	Error      string         `json:"error,omitempty"`      // Recovered error for panic events and cause for cancel events
+	State      string         `json:"state,omitempty"`      // New state for state events
+}
+
+// tracer writes lifecycle events to a writer, one JSON object per line
+type tracer struct {
+	lock sync.Mutex
+
+	encoder *json.Encoder
+	logger  Logger
+	failed  bool
+}
+
+func newTracer(w io.Writer, logger Logger) *tracer {
+	return &tracer{
+		encoder: json.NewEncoder(w),
+		logger:  logger,
+	}
+}
+
+func (t *tracer) write(event TraceEvent) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.encoder.Encode(event); err != nil && !t.failed {
+		// Only log the first error, since a broken writer usually stays broken
+		t.failed = true
+
+		t.logger.Warn("could not write trace event", "error", err)
+	}
+}
+
+// trace writes a lifecycle event if tracing is enabled
+func (m *GoroutineManager) trace(fn func() TraceEvent) {
+	if m.tracer == nil {
+		return
+	}
+
+	m.tracer.write(fn())
+}
+
+// traceEvent returns an event of type typ for a goroutine
+func (g *goroutine) traceEvent(typ TraceEventType, t time.Time) TraceEvent {
+	return TraceEvent{
+		Time:       t,
+		Type:       typ,
+		ID:         g.managedID,
+		Name:       g.options.name,
+		Tags:       g.options.tags,
+		Foreground: g.foreground,
+	}
+}
+
+// ReadTrace reads the events of a trace written with WithTrace, e.g. to replay
+// or analyze the shutdown sequence of a production process
+func ReadTrace(r io.Reader) ([]TraceEvent, error) {
+	var events []TraceEvent
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	for scanner.Scan() {
+		var event TraceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return events, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+// TraceSummary is an analysis of the events of a trace
+type TraceSummary struct {
+	Started  int // Number of started goroutines
+	Finished int // Number of finished goroutines
+	Panics   int // Number of recovered panics
+
+	Cancelled  time.Time    // Time at which the goroutine context was first cancelled, or zero
+	Stopped    time.Time    // Time at which the goroutine manager last stopped, or zero
+	StopOrder  []TraceEvent // Finish events of the goroutines that finished after the goroutine context was cancelled, in order
+	Unfinished []TraceEvent // Start events of the goroutines that never finished, e.g. ones that blocked a shutdown
+}
+
+// SummarizeTrace analyzes the events of a trace
+func SummarizeTrace(events []TraceEvent) TraceSummary {
+	var summary TraceSummary
+
+	running := map[uint64]TraceEvent{}
+	var order []uint64
+	for _, event := range events {
+		switch event.Type {
+		case TraceEventStart:
+			summary.Started++
+
+			running[event.ID] = event
+			order = append(order, event.ID)
+
+		case TraceEventFinish:
+			summary.Finished++
+
+			delete(running, event.ID)
+
+			if !summary.Cancelled.IsZero() {
+				summary.StopOrder = append(summary.StopOrder, event)
+			}
+
+		case TraceEventPanic:
+			summary.Panics++
+
+		case TraceEventCancel:
+			if summary.Cancelled.IsZero() {
+				summary.Cancelled = event.Time
+			}
+
+		case TraceEventState:
+			if event.State == StateStopped.String() {
+				summary.Stopped = event.Time
+			}
+		}
+	}
+
+	for _, id := range order {
+		if event, ok := running[id]; ok {
+			summary.Unfinished = append(summary.Unfinished, event)
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTrace(t *testing.T) {
+	t.Parallel()
+
+	var buf syncBuffer
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{}, WithTrace(&buf))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {}, WithGoroutineName("worker"), WithTags("io"))
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, WithGoroutineName("panicker"))
+	m.Wait()
+
+	events, err := ReadTrace(strings.NewReader(buf.String()))
+	require.NoError(t, err)
+
+	// Verify the goroutines' lifecycles are recorded.
+	var types []TraceEventType
+	for _, event := range events {
+		if event.Name == "worker" {
+			types = append(types, event.Type)
+
+			require.Equal(t, []string{"io"}, event.Tags)
+			require.True(t, event.Foreground)
+		}
+	}
+	require.Equal(t, []TraceEventType{TraceEventStart, TraceEventFinish}, types)
+
+	summary := SummarizeTrace(events)
+	require.Equal(t, 2, summary.Started)
+	require.Equal(t, 2, summary.Finished)
+	require.Equal(t, 1, summary.Panics)
+	require.False(t, summary.Cancelled.IsZero())
+	require.False(t, summary.Stopped.IsZero())
+	require.Empty(t, summary.Unfinished)
+}
+
+func TestSummarizeTrace(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	summary := SummarizeTrace([]TraceEvent{
+		{Time: now, Type: TraceEventStart, ID: 1, Name: "a"},
+		{Time: now, Type: TraceEventStart, ID: 2, Name: "b"},
+		{Time: now, Type: TraceEventStart, ID: 3, Name: "c"},
+		{Time: now.Add(time.Second), Type: TraceEventCancel, Error: "stopped"},
+		{Time: now.Add(2 * time.Second), Type: TraceEventFinish, ID: 3, Name: "c"},
+		{Time: now.Add(3 * time.Second), Type: TraceEventFinish, ID: 1, Name: "a"},
+	})
+
+	// Verify the shutdown sequence and the goroutine that blocked it are reconstructed.
+	require.Equal(t, now.Add(time.Second), summary.Cancelled)
+	require.Len(t, summary.StopOrder, 2)
+	require.Equal(t, "c", summary.StopOrder[0].Name)
+	require.Equal(t, "a", summary.StopOrder[1].Name)
+	require.Len(t, summary.Unfinished, 1)
+	require.Equal(t, "b", summary.Unfinished[0].Name)
+}
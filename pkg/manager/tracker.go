@@ -0,0 +1,189 @@
+package manager
+
+import (
+	"sync"
+)
+
+// Progress describes the progress of foreground goroutines finishing
+type Progress struct {
+	Remaining int    // Number of foreground goroutines (and panic collectors) that haven't finished yet
+	Finished  string // Name of the foreground goroutine that just finished; empty for the first event and unnamed goroutines
+}
+
+// progressSubscription buffers progress events for a WaitProgress() caller,
+// so that finishing goroutines never block on slow receivers
+type progressSubscription struct {
+	lock   sync.Mutex
+	events []Progress
+	notify chan struct{}
+}
+
+func (s *progressSubscription) push(p Progress) {
+	s.lock.Lock()
+	s.events = append(s.events, p)
+	s.lock.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// forward sends the buffered events to out until an event without remaining
+// goroutines was sent, and then closes out
+func (s *progressSubscription) forward(out chan<- Progress) {
+	defer close(out)
+
+	for range s.notify {
+		s.lock.Lock()
+		events := s.events
+		s.events = nil
+		s.lock.Unlock()
+
+		for _, p := range events {
+			out <- p
+
+			if p.Remaining == 0 {
+				return
+			}
+		}
+	}
+}
+
+// tracker keeps track of the running goroutines of a goroutine manager
+type tracker struct {
+	lock sync.Mutex
+
+	foreground    map[*goroutine]struct{}
+	subscriptions []*progressSubscription
+
+	done chan struct{} // Closed once the foreground goroutines of the current generation finish
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		foreground: map[*goroutine]struct{}{},
+	}
+}
+
+// add starts tracking a goroutine
+func (t *tracker) add(g *goroutine) {
+	if !g.foreground {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.foreground) == 0 {
+		t.done = make(chan struct{})
+	}
+
+	t.foreground[g] = struct{}{}
+}
+
+// remove stops tracking a goroutine and notifies progress subscribers
+func (t *tracker) remove(g *goroutine) {
+	if !g.foreground {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.foreground, g)
+
+	if len(t.foreground) == 0 {
+		close(t.done)
+		t.done = nil
+	}
+
+	t.publish(Progress{
+		Remaining: len(t.foreground),
+		Finished:  g.options.name,
+	})
+}
+
+// subscribe adds a progress subscription and sends it the current progress
+func (t *tracker) subscribe() *progressSubscription {
+	s := &progressSubscription{
+		notify: make(chan struct{}, 1),
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.subscriptions = append(t.subscriptions, s)
+
+	t.publish(Progress{
+		Remaining: len(t.foreground),
+	})
+
+	return s
+}
+
+// remaining returns the foreground goroutines that haven't finished yet
+func (t *tracker) remaining() []*goroutine {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	out := make([]*goroutine, 0, len(t.foreground))
+	for g := range t.foreground {
+		out = append(out, g)
+	}
+
+	return out
+}
+
+// allDone returns the channel that is closed once the foreground goroutines of
+// the current generation finish, or a closed channel if none are running
+func (t *tracker) allDone() <-chan struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.done == nil {
+		done := make(chan struct{})
+		close(done)
+
+		return done
+	}
+
+	return t.done
+}
+
+// publish sends a progress event to all subscriptions, removing them once no
+// foreground goroutines remain. It must be called with the lock held.
+func (t *tracker) publish(p Progress) {
+	for _, s := range t.subscriptions {
+		s.push(p)
+	}
+
+	if p.Remaining == 0 {
+		t.subscriptions = nil
+	}
+}
+
+// WaitProgress returns a channel that receives the progress of the foreground
+// goroutines finishing: first the number of remaining foreground goroutines,
+// and then an event with the new remaining count and the goroutine's name each
+// time one finishes. The channel is closed once no foreground goroutines
+// remain. It must be drained until it is closed.
+//
+// This can be used to report the progress of a shutdown, e.g. after calling
+// StopAllGoroutines().
+func (m *GoroutineManager) WaitProgress() <-chan Progress {
+	out := make(chan Progress)
+
+	go m.tracker.subscribe().forward(out)
+
+	return out
+}
+
+// AllDone returns a channel that is closed once all foreground goroutines of
+// the current generation finish, so that callers can select on it together
+// with other channels instead of blocking in Wait(). A generation starts when
+// a foreground goroutine is started while none are running; if none are
+// running, the returned channel is already closed.
+func (m *GoroutineManager) AllDone() <-chan struct{} {
+	return m.tracker.allDone()
+}
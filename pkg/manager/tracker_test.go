@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitProgress(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	done := map[string]chan any{}
+	for _, name := range []string{"a", "b", "c"} {
+		ch := make(chan any)
+		done[name] = ch
+
+		m.StartForegroundGoroutine(func(_ context.Context) {
+			<-ch
+		}, WithGoroutineName(name))
+	}
+
+	// Verify background goroutines are not counted.
+	m.StartBackgroundGoroutine(func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	progress := m.WaitProgress()
+	require.Equal(t, Progress{Remaining: 3}, <-progress)
+
+	// Verify an event is sent for each finished goroutine.
+	close(done["b"])
+	require.Equal(t, Progress{Remaining: 2, Finished: "b"}, <-progress)
+
+	close(done["a"])
+	require.Equal(t, Progress{Remaining: 1, Finished: "a"}, <-progress)
+
+	close(done["c"])
+	require.Equal(t, Progress{Remaining: 0, Finished: "c"}, <-progress)
+
+	// Verify the channel is closed once no goroutines remain.
+	_, ok := <-progress
+	require.False(t, ok)
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestWaitProgressNoGoroutines(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	progress := m.WaitProgress()
+	require.Equal(t, Progress{}, <-progress)
+
+	_, ok := <-progress
+	require.False(t, ok)
+}
+
+func TestAllDone(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	// Verify the channel is closed if no goroutines are running.
+	<-m.AllDone()
+
+	done := make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		<-done
+	})
+
+	first := m.AllDone()
+	require.Never(t, func() bool {
+		<-first
+		return true
+	}, 50*time.Millisecond, time.Millisecond)
+
+	// Verify the channel is closed once the generation finishes.
+	close(done)
+	<-first
+	m.Wait()
+
+	// Verify a new generation gets a new channel.
+	done = make(chan any)
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		<-done
+	})
+
+	second := m.AllDone()
+	require.NotEqual(t, first, second)
+
+	select {
+	case <-second:
+		t.Fatal("expected second generation to still be running")
+	default:
+	}
+
+	close(done)
+	<-second
+	m.Wait()
+	require.NoError(t, errs)
+}
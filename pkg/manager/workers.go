@@ -0,0 +1,43 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+)
+
+// defaultWorkerGroup is the name of worker groups started without a name
+const defaultWorkerGroup = "workers"
+
+// StartWorkers starts n foreground goroutines that run fn with their index
+// from 0 to n-1, e.g. to fan out work to a fixed number of workers. The name
+// set with WithGoroutineName is the name of the group, "workers" by default;
+// each worker is named after the group and its index, e.g. "fetch[3]", in
+// errors, while metrics and DurationsByName are recorded for the group as a
+// whole.
+func (m *GoroutineManager) StartWorkers(n int, fn func(ctx context.Context, workerID int), opts ...StartOption) {
+	group := newStartOptions(opts).name
+	if group == "" {
+		group = defaultWorkerGroup
+	}
+
+	for i := range n {
+		m.StartForegroundGoroutine(func(ctx context.Context) {
+			fn(ctx, i)
+		}, append(opts[:len(opts):len(opts)], withWorker(group, i))...)
+	}
+}
+
+// withWorker names a goroutine after its worker group and index
+func withWorker(group string, id int) StartOption {
+	return func(o *startOptions) {
+		o.name = fmt.Sprintf("%v[%v]", group, id)
+		o.group = group
+	}
+}
+
+// withGroup sets the name used instead of the goroutine's name in metrics
+func withGroup(group string) StartOption {
+	return func(o *startOptions) {
+		o.group = group
+	}
+}
@@ -0,0 +1,57 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartWorkers(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	var (
+		lock sync.Mutex
+		ids  []int
+	)
+	m.StartWorkers(4, func(_ context.Context, workerID int) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		ids = append(ids, workerID)
+	}, WithGoroutineName("fetch"))
+	m.Wait()
+
+	require.NoError(t, errs)
+	require.ElementsMatch(t, []int{0, 1, 2, 3}, ids)
+
+	// Verify the durations are recorded for the group.
+	stats := m.Stats()
+	require.Equal(t, uint64(4), stats.DurationsByName["fetch"].Count)
+	require.NotContains(t, stats.DurationsByName, "fetch[0]")
+}
+
+func TestStartWorkersPanic(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := NewGoroutineManager(context.Background(), &errs, GoroutineManagerHooks{})
+
+	m.StartWorkers(3, func(ctx context.Context, workerID int) {
+		if workerID == 2 {
+			panic(testErr)
+		}
+
+		<-ctx.Done()
+	})
+	m.Wait()
+
+	// Verify the panicking worker is identified in the error.
+	var p *PanicError
+	require.ErrorAs(t, errs, &p)
+	require.Equal(t, "workers[2]", p.Name)
+}
@@ -0,0 +1,196 @@
+// Package managertest provides utilities for testing code that uses a
+// goroutine manager.
+package managertest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+)
+
+// FakeClock is a manager.Clock whose time only moves when Advance() is called,
+// which makes time-based features of a goroutine manager testable without
+// sleeping.
+//
+// Usage:
+//
+//	clock := managertest.NewFakeClock(time.Now())
+//	m := manager.NewGoroutineManager(ctx, &errs, hooks, manager.WithClock(clock))
+//
+//	m.StartPeriodicGoroutine(time.Minute, fn)
+//	clock.BlockUntil(1)
+//	clock.Advance(time.Minute) // fn is called
+type FakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+
+	changed chan struct{}
+}
+
+// NewFakeClock creates a fake clock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) manager.Timer {
+	t := &fakeTimer{
+		clock: c,
+		c:     make(chan time.Time, 1),
+	}
+	t.Reset(d)
+
+	return t
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) manager.Timer {
+	t := &fakeTimer{
+		clock: c,
+		f:     f,
+	}
+	t.Reset(d)
+
+	return t
+}
+
+// Advance moves the time forward by d and fires all timers that are due, in
+// the order of their deadlines
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+
+	var due []*fakeTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if !t.deadline.After(now) {
+			due = append(due, t)
+		} else {
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.notify()
+	c.lock.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].deadline.Before(due[j].deadline)
+	})
+
+	for _, t := range due {
+		t.fire(now)
+	}
+}
+
+// Timers returns the number of timers that haven't fired or been stopped yet
+func (c *FakeClock) Timers() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are waiting, e.g. until a periodic
+// goroutine has started waiting for its next interval
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.lock.Lock()
+		if len(c.timers) >= n {
+			c.lock.Unlock()
+
+			return
+		}
+		changed := c.changed
+		c.lock.Unlock()
+
+		<-changed
+	}
+}
+
+// notify wakes up BlockUntil() callers. It must be called with the lock held.
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// remove removes a timer and returns true if it was waiting. It must be called
+// with the lock held.
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, candidate := range c.timers {
+		if candidate == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.notify()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+
+	c chan time.Time
+	f func()
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.lock.Lock()
+
+	active := t.clock.remove(t)
+
+	t.deadline = t.clock.now.Add(d)
+	if d > 0 {
+		t.clock.timers = append(t.clock.timers, t)
+		t.clock.notify()
+		t.clock.lock.Unlock()
+
+		return active
+	}
+
+	now := t.clock.now
+	t.clock.lock.Unlock()
+
+	t.fire(now)
+
+	return active
+}
+
+// fire sends the time on the timer's channel or calls its function
+func (t *fakeTimer) fire(now time.Time) {
+	if t.f != nil {
+		go t.f()
+
+		return
+	}
+
+	select {
+	case t.c <- now:
+	default:
+	}
+}
@@ -0,0 +1,131 @@
+package managertest
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+
+	timer := clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	require.True(t, stopped.Stop())
+	require.Equal(t, 1, clock.Timers())
+
+	// Verify timers don't fire before their deadline.
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("expected timer to not fire yet")
+	default:
+	}
+
+	// Verify timers fire once their deadline has passed.
+	clock.Advance(time.Millisecond)
+	require.Equal(t, start.Add(time.Second), <-timer.C())
+	require.Equal(t, 0, clock.Timers())
+
+	select {
+	case <-stopped.C():
+		t.Fatal("expected stopped timer to not fire")
+	default:
+	}
+
+	fired := make(chan any)
+	clock.AfterFunc(time.Minute, func() {
+		close(fired)
+	})
+
+	clock.Advance(time.Minute)
+	<-fired
+}
+
+func TestFakeClockPeriodicGoroutine(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.Now())
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{}, manager.WithClock(clock))
+
+	calls := make(chan any)
+	m.StartPeriodicGoroutine(time.Hour, func(_ context.Context) {
+		calls <- struct{}{}
+	})
+
+	// Verify each interval calls fn without sleeping.
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+		<-calls
+	}
+
+	m.StopAllGoroutines()
+	m.Wait()
+	require.NoError(t, errs)
+}
+
+func TestFakeClockTimeout(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	clock := NewFakeClock(start)
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{}, manager.WithClock(clock))
+
+	var deadline atomic.Value
+	m.Go(func(ctx context.Context) {
+		d, _ := ctx.Deadline()
+		deadline.Store(d)
+
+		<-ctx.Done()
+	}, manager.WithTimeout(time.Hour), manager.WithOverrunError())
+
+	// Verify the timeout is measured with the fake clock.
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	m.Wait()
+
+	require.True(t, start.Add(time.Hour).Equal(deadline.Load().(time.Time)))
+	require.ErrorIs(t, errs, manager.ErrGoroutineOverran)
+	require.ErrorIs(t, errs, context.DeadlineExceeded)
+}
+
+func TestFakeClockCleanupContext(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.Now())
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{}, manager.WithClock(clock), manager.WithCleanupTimeout(time.Minute))
+
+	m.StopAllGoroutines()
+
+	ctx, cancel := m.CleanupContext()
+	defer cancel()
+
+	// Verify the cleanup deadline follows the clock without sleeping.
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.Equal(t, clock.Now().Add(time.Minute), deadline)
+
+	clock.Advance(time.Minute - time.Second)
+	require.NoError(t, ctx.Err())
+
+	clock.Advance(time.Second)
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	m.Wait()
+	require.NoError(t, errs)
+}
@@ -0,0 +1,125 @@
+// Package oteladapter exports the metrics of a goroutine manager with
+// OpenTelemetry.
+package oteladapter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	AttributeManager = "manager" // Attribute containing the name of the goroutine manager
+
+	MetricPrefix = "goroutine_manager." // Prefix of the names of all instruments
+)
+
+// Recorder implements manager.StatsRecorder by recording the metrics of a
+// goroutine manager with OpenTelemetry instruments, e.g. the running
+// goroutines as a gauge, panics as a counter and goroutine durations as a
+// histogram. manager.Metric* names are prefixed with MetricPrefix, and
+// manager.Label* labels are added as attributes alongside AttributeManager.
+type Recorder struct {
+	meter       metric.Meter
+	managerName string
+
+	lock       sync.Mutex
+	counters   map[string]metric.Float64Counter
+	gauges     map[string]metric.Float64Gauge
+	histograms map[string]metric.Float64Histogram
+}
+
+// NewRecorder creates a recorder that records the metrics of the goroutine
+// manager named managerName with meter.
+//
+// Usage:
+//
+//	manager.NewGoroutineManager(ctx, &errs, hooks, manager.WithStatsRecorder(oteladapter.NewRecorder(meter, "my-manager")))
+func NewRecorder(meter metric.Meter, managerName string) *Recorder {
+	return &Recorder{
+		meter:       meter,
+		managerName: managerName,
+
+		counters:   map[string]metric.Float64Counter{},
+		gauges:     map[string]metric.Float64Gauge{},
+		histograms: map[string]metric.Float64Histogram{},
+	}
+}
+
+func (r *Recorder) AddCounter(name string, delta float64, labels map[string]string) {
+	counter, ok := instrument(r, r.counters, name, func(name string) (metric.Float64Counter, error) {
+		return r.meter.Float64Counter(name)
+	})
+	if ok {
+		counter.Add(context.Background(), delta, r.attributes(labels))
+	}
+}
+
+func (r *Recorder) SetGauge(name string, value float64, labels map[string]string) {
+	gauge, ok := instrument(r, r.gauges, name, func(name string) (metric.Float64Gauge, error) {
+		return r.meter.Float64Gauge(name)
+	})
+	if ok {
+		gauge.Record(context.Background(), value, r.attributes(labels))
+	}
+}
+
+func (r *Recorder) ObserveHistogram(name string, value float64, labels map[string]string) {
+	histogram, ok := instrument(r, r.histograms, name, func(name string) (metric.Float64Histogram, error) {
+		if name != MetricPrefix+manager.MetricGoroutineDuration {
+			return r.meter.Float64Histogram(name)
+		}
+
+		buckets := make([]float64, len(manager.DefaultDurationBuckets))
+		for i, bucket := range manager.DefaultDurationBuckets {
+			buckets[i] = bucket.Seconds()
+		}
+
+		return r.meter.Float64Histogram(name, metric.WithUnit("s"), metric.WithExplicitBucketBoundaries(buckets...))
+	})
+	if ok {
+		histogram.Record(context.Background(), value, r.attributes(labels))
+	}
+}
+
+// instrument returns the instrument for the metric name from instruments,
+// creating it if it doesn't exist yet. Errors are passed to otel.Handle.
+func instrument[T any](r *Recorder, instruments map[string]T, name string, create func(name string) (T, error)) (T, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if i, ok := instruments[name]; ok {
+		return i, true
+	}
+
+	i, err := create(MetricPrefix + name)
+	if err != nil {
+		otel.Handle(err)
+
+		return i, false
+	}
+
+	instruments[name] = i
+
+	return i, true
+}
+
+// attributes returns the attributes for labels
+func (r *Recorder) attributes(labels map[string]string) metric.MeasurementOption {
+	attrs := make([]attribute.KeyValue, 0, len(labels)+1)
+
+	// Managers named with manager.WithName add their name as a label already
+	if _, ok := labels[manager.LabelManager]; !ok {
+		attrs = append(attrs, attribute.String(AttributeManager, r.managerName))
+	}
+
+	for key, value := range labels {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	return metric.WithAttributes(attrs...)
+}
@@ -0,0 +1,61 @@
+package oteladapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{}, manager.WithStatsRecorder(NewRecorder(meter, "scheduler")))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {}, manager.WithGoroutineName("worker"))
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic("test")
+	}, manager.WithGoroutineName("worker"), manager.WithPanicPolicy(manager.PanicPolicyRecord))
+	m.Wait()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	metrics := map[string]metricdata.Metrics{}
+	for _, metric := range rm.ScopeMetrics[0].Metrics {
+		metrics[metric.Name] = metric
+	}
+
+	// Verify the instruments are recorded with the manager and goroutine attributes.
+	running, ok := metrics[MetricPrefix+manager.MetricGoroutinesRunning].Data.(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, running.DataPoints, 1)
+	require.Equal(t, 0.0, running.DataPoints[0].Value)
+	require.Equal(t, attribute.NewSet(
+		attribute.String(AttributeManager, "scheduler"),
+		attribute.String(manager.LabelKind, "foreground"),
+	), running.DataPoints[0].Attributes)
+
+	panics, ok := metrics[MetricPrefix+manager.MetricPanics].Data.(metricdata.Sum[float64])
+	require.True(t, ok)
+	require.Len(t, panics.DataPoints, 1)
+	require.Equal(t, 1.0, panics.DataPoints[0].Value)
+
+	durations, ok := metrics[MetricPrefix+manager.MetricGoroutineDuration].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, durations.DataPoints, 1)
+	require.Equal(t, uint64(2), durations.DataPoints[0].Count)
+	require.Len(t, durations.DataPoints[0].Bounds, len(manager.DefaultDurationBuckets))
+
+	goroutine, _ := durations.DataPoints[0].Attributes.Value(manager.LabelGoroutine)
+	require.Equal(t, "worker", goroutine.AsString())
+}
@@ -0,0 +1,180 @@
+// Package prometheusadapter exports the statistics of a goroutine manager as
+// Prometheus metrics.
+package prometheusadapter
+
+import (
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	LabelManager   = "manager"   // Label containing the name of the goroutine manager
+	LabelGoroutine = "goroutine" // Label containing the name of the goroutine
+	LabelQueue     = "queue"     // Label containing the name of the queue
+	LabelKind      = "kind"      // Label containing the kind of goroutine, either "foreground" or "background"
+)
+
+var (
+	startedDesc = prometheus.NewDesc(
+		"goroutine_manager_goroutines_started_total",
+		"Total number of started goroutines.",
+		[]string{LabelManager, LabelKind},
+		nil,
+	)
+	runningDesc = prometheus.NewDesc(
+		"goroutine_manager_goroutines_running",
+		"Number of goroutines that haven't finished yet.",
+		[]string{LabelManager, LabelKind},
+		nil,
+	)
+	finishedDesc = prometheus.NewDesc(
+		"goroutine_manager_goroutines_finished_total",
+		"Total number of finished goroutines.",
+		[]string{LabelManager, LabelKind},
+		nil,
+	)
+
+	durationDesc = prometheus.NewDesc(
+		"goroutine_manager_goroutine_duration_seconds",
+		"Duration of finished goroutines.",
+		[]string{LabelManager, LabelGoroutine},
+		nil,
+	)
+
+	queueDepthDesc = prometheus.NewDesc(
+		"goroutine_manager_queue_depth",
+		"Number of currently queued tasks.",
+		[]string{LabelManager, LabelQueue},
+		nil,
+	)
+	queueCapacityDesc = prometheus.NewDesc(
+		"goroutine_manager_queue_capacity",
+		"Maximum number of queued tasks.",
+		[]string{LabelManager, LabelQueue},
+		nil,
+	)
+	queueEnqueuedDesc = prometheus.NewDesc(
+		"goroutine_manager_queue_enqueued_total",
+		"Total number of enqueued tasks.",
+		[]string{LabelManager, LabelQueue},
+		nil,
+	)
+	queueRejectedDesc = prometheus.NewDesc(
+		"goroutine_manager_queue_rejected_total",
+		"Total number of tasks rejected because the queue was full.",
+		[]string{LabelManager, LabelQueue},
+		nil,
+	)
+	queueWaitDesc = prometheus.NewDesc(
+		"goroutine_manager_queue_wait_seconds",
+		"Time tasks spent in the queue before a worker started them.",
+		[]string{LabelManager, LabelQueue},
+		nil,
+	)
+)
+
+// Collector is a Prometheus collector for the statistics of a goroutine
+// manager
+type Collector struct {
+	m           *manager.GoroutineManager
+	managerName string
+}
+
+// NewCollector creates a Prometheus collector for the statistics of m.
+// managerName is added to each metric as a label to distinguish managers; if
+// it is empty, the name set with manager.WithName is used.
+func NewCollector(m *manager.GoroutineManager, managerName string) *Collector {
+	if managerName == "" {
+		managerName = m.Name()
+	}
+
+	return &Collector{m, managerName}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	collect(ch, c.m, c.managerName)
+}
+
+// RegistryCollector is a Prometheus collector for the statistics of all
+// goroutine managers in the process-wide registry, see manager.Register()
+type RegistryCollector struct{}
+
+// NewRegistryCollector creates a Prometheus collector for the statistics of
+// all registered goroutine managers. The name each manager is registered
+// under is added to its metrics as a label.
+func NewRegistryCollector() *RegistryCollector {
+	return &RegistryCollector{}
+}
+
+func (c *RegistryCollector) Describe(ch chan<- *prometheus.Desc) {
+	describe(ch)
+}
+
+func (c *RegistryCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, name := range manager.Registered() {
+		if m, ok := manager.Lookup(name); ok {
+			collect(ch, m, name)
+		}
+	}
+}
+
+// describe sends the descriptors of all metrics
+func describe(ch chan<- *prometheus.Desc) {
+	ch <- startedDesc
+	ch <- runningDesc
+	ch <- finishedDesc
+
+	ch <- durationDesc
+
+	ch <- queueDepthDesc
+	ch <- queueCapacityDesc
+	ch <- queueEnqueuedDesc
+	ch <- queueRejectedDesc
+	ch <- queueWaitDesc
+}
+
+// collect sends the metrics of a goroutine manager
+func collect(ch chan<- prometheus.Metric, m *manager.GoroutineManager, managerName string) {
+	stats := m.Stats()
+
+	for kind, counts := range map[string]manager.GoroutineCounts{
+		"foreground": stats.Foreground,
+		"background": stats.Background,
+	} {
+		ch <- prometheus.MustNewConstMetric(startedDesc, prometheus.CounterValue, float64(counts.Started), managerName, kind)
+		ch <- prometheus.MustNewConstMetric(runningDesc, prometheus.GaugeValue, float64(counts.Running), managerName, kind)
+		ch <- prometheus.MustNewConstMetric(finishedDesc, prometheus.CounterValue, float64(counts.Finished), managerName, kind)
+	}
+
+	for name, h := range stats.DurationsByName {
+		ch <- durationHistogram(durationDesc, h, managerName, name)
+	}
+
+	for name, q := range stats.Queues {
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(q.Depth), managerName, name)
+		ch <- prometheus.MustNewConstMetric(queueCapacityDesc, prometheus.GaugeValue, float64(q.Capacity), managerName, name)
+		ch <- prometheus.MustNewConstMetric(queueEnqueuedDesc, prometheus.CounterValue, float64(q.Enqueued), managerName, name)
+		ch <- prometheus.MustNewConstMetric(queueRejectedDesc, prometheus.CounterValue, float64(q.Rejected), managerName, name)
+		ch <- durationHistogram(queueWaitDesc, q.WaitTimes, managerName, name)
+	}
+}
+
+// durationHistogram converts a duration histogram to a Prometheus histogram
+// with cumulative buckets in seconds
+func durationHistogram(desc *prometheus.Desc, h manager.DurationHistogram, labelValues ...string) prometheus.Metric {
+	var (
+		buckets    = map[float64]uint64{}
+		cumulative uint64
+	)
+	for i, bound := range h.Buckets {
+		cumulative += h.Counts[i]
+
+		buckets[bound.Seconds()] = cumulative
+	}
+
+	return prometheus.MustNewConstHistogram(desc, h.Count, h.Sum.Seconds(), buckets, labelValues...)
+}
@@ -0,0 +1,196 @@
+package prometheusadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{})
+
+	for i := 0; i < 2; i++ {
+		m.StartForegroundGoroutine(func(_ context.Context) {}, manager.WithGoroutineName("worker"))
+	}
+	m.Wait()
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(NewCollector(m, "scheduler")))
+
+	// Verify the histogram is exported with the count of finished goroutines.
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var durations *dto.MetricFamily
+	for _, family := range families {
+		if family.GetName() == "goroutine_manager_goroutine_duration_seconds" {
+			durations = family
+		}
+	}
+	require.NotNil(t, durations)
+	require.Len(t, durations.GetMetric(), 1)
+
+	metric := durations.GetMetric()[0]
+	labels := map[string]string{}
+	for _, label := range metric.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	require.Equal(t, map[string]string{LabelManager: "scheduler", LabelGoroutine: "worker"}, labels)
+	require.Equal(t, uint64(2), metric.GetHistogram().GetSampleCount())
+	require.Len(t, metric.GetHistogram().GetBucket(), len(manager.DefaultDurationBuckets))
+}
+
+func TestCollectorQueues(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{})
+	defer m.Wait()
+	defer m.StopAllGoroutines()
+
+	q := m.NewQueue("uploads", 1, 3)
+
+	// Keep the worker busy so that the next task stays queued
+	started := make(chan struct{})
+	require.NoError(t, q.TryEnqueue(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	}))
+	<-started
+
+	require.NoError(t, q.TryEnqueue(func(_ context.Context) {}))
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(NewCollector(m, "scheduler")))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == LabelQueue {
+					require.Equal(t, "uploads", label.GetValue())
+
+					values[family.GetName()] = metric.GetGauge().GetValue() + metric.GetCounter().GetValue() + float64(metric.GetHistogram().GetSampleCount())
+				}
+			}
+		}
+	}
+
+	// Verify the queue metrics are exported.
+	require.Equal(t, map[string]float64{
+		"goroutine_manager_queue_depth":          1,
+		"goroutine_manager_queue_capacity":       3,
+		"goroutine_manager_queue_enqueued_total": 2,
+		"goroutine_manager_queue_rejected_total": 0,
+		"goroutine_manager_queue_wait_seconds":   1,
+	}, values)
+}
+
+func TestCollectorCounts(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {})
+	m.Wait()
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(NewCollector(m, "scheduler")))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == LabelKind && label.GetValue() == "foreground" {
+					values[family.GetName()] = metric.GetGauge().GetValue() + metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	// Verify the foreground counts are exported.
+	require.Equal(t, map[string]float64{
+		"goroutine_manager_goroutines_started_total":  1,
+		"goroutine_manager_goroutines_running":        0,
+		"goroutine_manager_goroutines_finished_total": 1,
+	}, values)
+}
+
+func TestRegistryCollector(t *testing.T) {
+	// Not parallel since the registry is package-level state.
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {})
+	m.Wait()
+
+	require.NoError(t, manager.Register("scheduler", m))
+	defer manager.Unregister("scheduler")
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(NewRegistryCollector()))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	// Verify the metrics are labeled with the registered name.
+	found := false
+	for _, family := range families {
+		if family.GetName() != "goroutine_manager_goroutines_started_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == LabelManager {
+					require.Equal(t, "scheduler", label.GetValue())
+
+					found = true
+				}
+			}
+		}
+	}
+	require.True(t, found)
+}
+
+func TestCollectorManagerName(t *testing.T) {
+	t.Parallel()
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{}, manager.WithName("scheduler"))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {})
+	m.Wait()
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(NewCollector(m, "")))
+
+	// Verify the name set with WithName is used by default.
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.NotEmpty(t, families)
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == LabelManager {
+					require.Equal(t, "scheduler", label.GetValue())
+				}
+			}
+		}
+	}
+}
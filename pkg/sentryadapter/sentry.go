@@ -0,0 +1,81 @@
+// Package sentryadapter reports panics collected by a goroutine manager to
+// Sentry-compatible crash reporting services.
+package sentryadapter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+)
+
+const (
+	TagGoroutineName = "goroutine.name" // Tag containing the name of the goroutine that panicked
+	TagGoroutineTags = "goroutine.tags" // Tag containing the comma-separated tags of the goroutine that panicked
+	TagManagerName   = "manager.name"   // Tag containing the name of the goroutine manager
+
+	ContextGoroutine = "goroutine" // Event context containing the goroutine's ID, start time and runtime
+)
+
+// OnPanic creates an OnPanic hook that reports panics to Sentry using hub.
+// managerName is added to each event as a tag to distinguish managers; if it
+// is empty, the name set with manager.WithName is used.
+//
+// Usage:
+//
+//	manager.GoroutineManagerHooks{
+//		OnPanic: sentryadapter.OnPanic(sentry.CurrentHub(), "scheduler"),
+//	}
+func OnPanic(hub *sentry.Hub, managerName string) func(info manager.GoroutineInfo, err *manager.PanicError) {
+	return func(info manager.GoroutineInfo, err *manager.PanicError) {
+		hub.CaptureEvent(NewEvent(info, err, managerName))
+	}
+}
+
+// NewEvent creates a Sentry event for a panic error, including the stack
+// trace and metadata of the goroutine at the time of the panic
+func NewEvent(info manager.GoroutineInfo, err *manager.PanicError, managerName string) *sentry.Event {
+	frames := err.Frames()
+
+	// Sentry expects frames to be ordered from the outermost to the innermost call
+	stacktrace := &sentry.Stacktrace{}
+	for i := len(frames) - 1; i >= 0; i-- {
+		stacktrace.Frames = append(stacktrace.Frames, sentry.NewFrame(frames[i]))
+	}
+
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	event.Timestamp = err.Time
+	event.Exception = []sentry.Exception{
+		{
+			Type:       fmt.Sprintf("%T", err.Value),
+			Value:      err.Error(),
+			Stacktrace: stacktrace,
+		},
+	}
+
+	event.Contexts[ContextGoroutine] = sentry.Context{
+		"id":      info.ID,
+		"started": info.Started,
+		"runtime": info.Runtime.String(),
+	}
+
+	if info.Name != "" {
+		event.Tags[TagGoroutineName] = info.Name
+	}
+
+	if len(info.Tags) > 0 {
+		event.Tags[TagGoroutineTags] = strings.Join(info.Tags, ",")
+	}
+
+	if managerName == "" {
+		managerName = info.Manager
+	}
+
+	if managerName != "" {
+		event.Tags[TagManagerName] = managerName
+	}
+
+	return event
+}
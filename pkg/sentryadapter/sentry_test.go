@@ -0,0 +1,71 @@
+package sentryadapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/stretchr/testify/require"
+)
+
+var testErr = errors.New("test error")
+
+type testTransport struct {
+	lock   sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *testTransport) Flush(time.Duration) bool { return true }
+
+func (t *testTransport) Configure(sentry.ClientOptions) {}
+
+func (t *testTransport) SendEvent(event *sentry.Event) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.events = append(t.events, event)
+}
+
+func TestOnPanic(t *testing.T) {
+	t.Parallel()
+
+	transport := &testTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Transport: transport,
+	})
+	require.NoError(t, err)
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{
+		OnPanic: OnPanic(sentry.NewHub(client, sentry.NewScope()), "scheduler"),
+	})
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, manager.WithGoroutineName("worker"), manager.WithTags("a", "b"))
+	m.Wait()
+	require.ErrorIs(t, errs, testErr)
+
+	// Verify the panic was reported with tags and a stack trace.
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+
+	require.Len(t, transport.events, 1)
+
+	event := transport.events[0]
+	require.Equal(t, "worker", event.Tags[TagGoroutineName])
+	require.Equal(t, "a,b", event.Tags[TagGoroutineTags])
+	require.Equal(t, "scheduler", event.Tags[TagManagerName])
+	require.Contains(t, event.Contexts[ContextGoroutine], "runtime")
+	require.Len(t, event.Exception, 1)
+	require.Equal(t, "worker: test error", event.Exception[0].Value)
+	require.Equal(t, "*errors.errorString", event.Exception[0].Type)
+
+	frames := event.Exception[0].Stacktrace.Frames
+	require.NotEmpty(t, frames)
+	require.Equal(t, "TestOnPanic.func1", frames[len(frames)-1].Function)
+}
@@ -0,0 +1,71 @@
+// Package zapadapter logs the lifecycle and panics of a goroutine manager with
+// zap.
+package zapadapter
+
+import (
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"go.uber.org/zap"
+)
+
+const (
+	FieldManager       = "manager"        // Field containing the name of the goroutine manager, if set with manager.WithName
+	FieldGoroutineID   = "goroutine.id"   // Field containing the ID of the goroutine that panicked
+	FieldGoroutineName = "goroutine.name" // Field containing the name of the goroutine that panicked
+	FieldGoroutineTags = "goroutine.tags" // Field containing the tags of the goroutine that panicked
+	FieldStarted       = "started"        // Field containing the time at which the goroutine was started
+	FieldRuntime       = "runtime"        // Field containing how long the goroutine had been running
+	FieldStack         = "stack"          // Field containing the stack trace of the panic
+)
+
+// Logger implements manager.Logger using zap
+type Logger struct {
+	logger *zap.SugaredLogger
+}
+
+// NewLogger creates a manager.Logger that logs to logger.
+//
+// Usage:
+//
+//	manager.NewGoroutineManager(ctx, &errs, hooks, manager.WithLogger(zapadapter.NewLogger(logger)))
+func NewLogger(logger *zap.Logger) *Logger {
+	return &Logger{
+		logger: logger.WithOptions(zap.AddCallerSkip(1)).Sugar(),
+	}
+}
+
+func (l *Logger) Debug(msg string, keysAndValues ...any) {
+	l.logger.Debugw(msg, keysAndValues...)
+}
+
+func (l *Logger) Info(msg string, keysAndValues ...any) {
+	l.logger.Infow(msg, keysAndValues...)
+}
+
+func (l *Logger) Warn(msg string, keysAndValues ...any) {
+	l.logger.Warnw(msg, keysAndValues...)
+}
+
+func (l *Logger) Error(msg string, keysAndValues ...any) {
+	l.logger.Errorw(msg, keysAndValues...)
+}
+
+// OnPanic creates an OnPanic hook that logs panics with the goroutine's
+// metadata and the stack trace to logger
+func OnPanic(logger *zap.Logger) func(info manager.GoroutineInfo, err *manager.PanicError) {
+	return func(info manager.GoroutineInfo, err *manager.PanicError) {
+		fields := []zap.Field{
+			zap.Error(err),
+			zap.Uint64(FieldGoroutineID, info.ID),
+			zap.String(FieldGoroutineName, info.Name),
+			zap.Strings(FieldGoroutineTags, info.Tags),
+			zap.Time(FieldStarted, info.Started),
+			zap.Duration(FieldRuntime, info.Runtime),
+			zap.ByteString(FieldStack, err.Stack),
+		}
+		if info.Manager != "" {
+			fields = append(fields, zap.String(FieldManager, info.Manager))
+		}
+
+		logger.Error("goroutine panicked", fields...)
+	}
+}
@@ -0,0 +1,48 @@
+package zapadapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var testErr = errors.New("test error")
+
+func TestZap(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{
+		OnPanic: OnPanic(logger),
+	}, manager.WithLogger(NewLogger(logger)))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, manager.WithGoroutineName("worker"), manager.WithTags("db"))
+	m.Wait()
+
+	// Verify the panic is logged by both the logger and the hook.
+	recovered := logs.FilterMessage("recovered panic in goroutine").All()
+	require.Len(t, recovered, 1)
+	require.Equal(t, "worker", recovered[0].ContextMap()["goroutine"])
+
+	panicked := logs.FilterMessage("goroutine panicked").All()
+	require.Len(t, panicked, 1)
+	require.Equal(t, zapcore.ErrorLevel, panicked[0].Level)
+
+	fields := panicked[0].ContextMap()
+	require.Equal(t, uint64(1), fields[FieldGoroutineID])
+	require.Equal(t, "worker", fields[FieldGoroutineName])
+	require.Equal(t, []any{"db"}, fields[FieldGoroutineTags])
+	require.Contains(t, fields["error"], testErr.Error())
+	require.NotEmpty(t, fields[FieldStack])
+}
@@ -0,0 +1,70 @@
+// Package zerologadapter logs the lifecycle and panics of a goroutine manager
+// with zerolog.
+package zerologadapter
+
+import (
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/rs/zerolog"
+)
+
+const (
+	FieldManager       = "manager"        // Field containing the name of the goroutine manager, if set with manager.WithName
+	FieldGoroutineID   = "goroutine.id"   // Field containing the ID of the goroutine that panicked
+	FieldGoroutineName = "goroutine.name" // Field containing the name of the goroutine that panicked
+	FieldGoroutineTags = "goroutine.tags" // Field containing the tags of the goroutine that panicked
+	FieldStarted       = "started"        // Field containing the time at which the goroutine was started
+	FieldRuntime       = "runtime"        // Field containing how long the goroutine had been running
+	FieldStack         = "stack"          // Field containing the stack trace of the panic
+)
+
+// Logger implements manager.Logger using zerolog
+type Logger struct {
+	logger zerolog.Logger
+}
+
+// NewLogger creates a manager.Logger that logs to logger.
+//
+// Usage:
+//
+//	manager.NewGoroutineManager(ctx, &errs, hooks, manager.WithLogger(zerologadapter.NewLogger(logger)))
+func NewLogger(logger zerolog.Logger) *Logger {
+	return &Logger{
+		logger: logger,
+	}
+}
+
+func (l *Logger) Debug(msg string, keysAndValues ...any) {
+	l.logger.Debug().Fields(keysAndValues).Msg(msg)
+}
+
+func (l *Logger) Info(msg string, keysAndValues ...any) {
+	l.logger.Info().Fields(keysAndValues).Msg(msg)
+}
+
+func (l *Logger) Warn(msg string, keysAndValues ...any) {
+	l.logger.Warn().Fields(keysAndValues).Msg(msg)
+}
+
+func (l *Logger) Error(msg string, keysAndValues ...any) {
+	l.logger.Error().Fields(keysAndValues).Msg(msg)
+}
+
+// OnPanic creates an OnPanic hook that logs panics with the goroutine's
+// metadata and the stack trace to logger
+func OnPanic(logger zerolog.Logger) func(info manager.GoroutineInfo, err *manager.PanicError) {
+	return func(info manager.GoroutineInfo, err *manager.PanicError) {
+		event := logger.Error().
+			Err(err).
+			Uint64(FieldGoroutineID, info.ID).
+			Str(FieldGoroutineName, info.Name).
+			Strs(FieldGoroutineTags, info.Tags).
+			Time(FieldStarted, info.Started).
+			Dur(FieldRuntime, info.Runtime).
+			Bytes(FieldStack, err.Stack)
+		if info.Manager != "" {
+			event = event.Str(FieldManager, info.Manager)
+		}
+
+		event.Msg("goroutine panicked")
+	}
+}
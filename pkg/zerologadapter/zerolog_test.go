@@ -0,0 +1,52 @@
+package zerologadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/loopholelabs/goroutine-manager/pkg/manager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+var testErr = errors.New("test error")
+
+func TestZerolog(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := zerolog.New(zerolog.SyncWriter(&buf)).Level(zerolog.DebugLevel)
+
+	var errs error
+	m := manager.NewGoroutineManager(context.Background(), &errs, manager.GoroutineManagerHooks{
+		OnPanic: OnPanic(logger),
+	}, manager.WithLogger(NewLogger(logger)))
+
+	m.StartForegroundGoroutine(func(_ context.Context) {
+		panic(testErr)
+	}, manager.WithGoroutineName("worker"), manager.WithTags("db"))
+	m.Wait()
+
+	entries := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+		entries[entry[zerolog.MessageFieldName].(string)] = entry
+	}
+
+	// Verify the panic is logged by both the logger and the hook.
+	require.Equal(t, "worker", entries["recovered panic in goroutine"]["goroutine"])
+
+	panicked := entries["goroutine panicked"]
+	require.Equal(t, "error", panicked[zerolog.LevelFieldName])
+	require.EqualValues(t, 1, panicked[FieldGoroutineID])
+	require.Equal(t, "worker", panicked[FieldGoroutineName])
+	require.Equal(t, []any{"db"}, panicked[FieldGoroutineTags])
+	require.Contains(t, panicked[zerolog.ErrorFieldName], testErr.Error())
+	require.NotEmpty(t, panicked[FieldStack])
+}